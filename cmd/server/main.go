@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 
@@ -14,6 +15,7 @@ import (
 func main() {
 	configFile := flag.String("config", "ssjitsi.yaml", "Путь к файлу конфигурации")
 	help := flag.Bool("help", false, "Показать справку")
+	dryRun := flag.Bool("dry-run", false, "Показать шаги сценариев входа ботов и выйти")
 	flag.Parse()
 
 	if *help {
@@ -25,14 +27,29 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Дублируем журнал в буфер, чтобы отдавать его через API
+	logs := ssjitsi.NewLogBuffer(1000)
+	log.SetOutput(io.MultiWriter(os.Stderr, logs))
+
 	// Загружаем конфигурацию
 	config, err := ssjitsi.LoadConfig(*configFile)
 	if err != nil {
 		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
 	}
 
+	// Пробный прогон: печатаем сценарии входа без запуска браузеров
+	if *dryRun {
+		for i := range config.Bots {
+			if err := config.Bots[i].DryRunJoin(os.Stdout); err != nil {
+				log.Fatalf("Ошибка сценария входа бота %d: %v", i+1, err)
+			}
+		}
+		os.Exit(0)
+	}
+
 	// Создаем HTTP сервер с авторизацией
 	server := ssjitsi.NewHttpServer(config.WebUsername, config.WebPassword)
+	server.SetLogBuffer(logs)
 
 	// Создаем embedded сервер с встроенным UI и авторизацией
 	router := ssjitsi.NewEmbeddedServer(server, config.WebUsername, config.WebPassword)
@@ -55,7 +72,7 @@ func main() {
 		botConfig.ID = uuid.New().String()
 
 		// Устанавливаем начальный статус
-		botConfig.SetStatus("stopped")
+		botConfig.SetStatus(ssjitsi.StatusStopped)
 
 		log.Printf("Запуск бота %d: комната '%s', имя '%s'", i+1, botConfig.Room, botConfig.BotName)
 
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sheff.online/ssjitsi/internal/pkg/ssjitsi"
+)
+
+// Ограничения времени запросов
+const (
+	requestTimeout        = 60 * time.Second // Весь JSON запрос
+	responseHeaderTimeout = 60 * time.Second // Ожидание ответа при загрузке файла
+)
+
+// Client обращается к REST API запущенного сервера
+type Client struct {
+	Server   string
+	Username string
+	Password string
+	http     *http.Client
+	stream   *http.Client // Для загрузок: без общего таймаута, тело может идти долго
+}
+
+func NewClient(server, username, password string) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+	return &Client{
+		Server:   strings.TrimRight(server, "/"),
+		Username: username,
+		Password: password,
+		http:     &http.Client{Timeout: requestTimeout},
+		stream:   &http.Client{Transport: transport},
+	}
+}
+
+// do выполняет запрос к /api/v1 и проверяет код ответа
+func (c *Client) do(method, path string) (*http.Response, error) {
+	return c.doWith(c.http, method, path)
+}
+
+// doWith выполняет запрос указанным клиентом
+func (c *Client) doWith(client *http.Client, method, path string) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.Server+"/api/v1"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, apiErr.Message)
+		}
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return resp, nil
+}
+
+func (c *Client) getJSON(path string, v interface{}) error {
+	resp, err := c.do(http.MethodGet, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *Client) postJSON(path string, v interface{}) error {
+	resp, err := c.do(http.MethodPost, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// download копирует тело ответа в w; время передачи не ограничено
+func (c *Client) download(path string, w io.Writer) error {
+	resp, err := c.doWith(c.stream, http.MethodGet, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (c *Client) Bots() ([]ssjitsi.BotInfo, error) {
+	var bots []ssjitsi.BotInfo
+	err := c.getJSON("/bots", &bots)
+	return bots, err
+}
+
+// ResolveBot находит ID бота по полному ID, префиксу ID, имени бота или комнате
+func (c *Client) ResolveBot(ref string) (string, error) {
+	bots, err := c.Bots()
+	if err != nil {
+		return "", err
+	}
+	var found []string
+	for _, b := range bots {
+		if b.ID == ref {
+			return b.ID, nil
+		}
+		if strings.HasPrefix(b.ID, ref) || b.BotName == ref || b.Room == ref {
+			found = append(found, b.ID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("bot %q not found", ref)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("bot %q is ambiguous: %s", ref, strings.Join(found, ", "))
+	}
+}
+
+// Action вызывает команду управления ботом: start, stop, restart
+func (c *Client) Action(id, action string) (map[string]interface{}, error) {
+	var res map[string]interface{}
+	err := c.postJSON("/"+url.PathEscape(id)+"/"+action, &res)
+	return res, err
+}
+
+func (c *Client) Events(id string, since int64) ([]ssjitsi.Event, error) {
+	var events []ssjitsi.Event
+	err := c.getJSON(fmt.Sprintf("/%s/events?since=%d", url.PathEscape(id), since), &events)
+	return events, err
+}
+
+func (c *Client) Logs(since int64) ([]ssjitsi.Event, error) {
+	var lines []ssjitsi.Event
+	err := c.getJSON(fmt.Sprintf("/logs?since=%d", since), &lines)
+	return lines, err
+}
+
+func (c *Client) Screenshot(id string, w io.Writer) error {
+	return c.download("/"+url.PathEscape(id)+"/screenshot", w)
+}
+
+func (c *Client) Sessions() ([]ssjitsi.SessionInfo, error) {
+	var sessions []ssjitsi.SessionInfo
+	err := c.getJSON("/sessions", &sessions)
+	return sessions, err
+}
+
+func (c *Client) SessionFiles(sid string) ([]ssjitsi.SessionFile, error) {
+	var files []ssjitsi.SessionFile
+	err := c.getJSON("/sessions/"+url.PathEscape(sid), &files)
+	return files, err
+}
+
+func (c *Client) DownloadFile(sid, name string, w io.Writer) error {
+	return c.download("/sessions/"+url.PathEscape(sid)+"/files/"+escapePath(name), w)
+}
+
+// escapePath экранирует каждый сегмент относительного пути
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i := range parts {
+		parts[i] = url.PathEscape(parts[i])
+	}
+	return strings.Join(parts, "/")
+}
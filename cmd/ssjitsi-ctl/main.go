@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	"sheff.online/ssjitsi/internal/pkg/ssjitsi"
+)
+
+// CtlConfig содержит параметры подключения к серверу из файла конфигурации
+type CtlConfig struct {
+	Server   string `yaml:"server"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+const usage = `ssjitsi-ctl - управление запущенным сервером JitsiBot
+
+Использование:
+  ssjitsi-ctl [флаги] <команда> [аргументы]
+
+Команды:
+  bots                          список ботов и их статусы
+  start|stop|restart <bot>      управление ботом
+  events <bot> [-f]             события бота
+  logs [-f]                     журнал сервера
+  screenshot <bot> [-o файл]    сохранить скриншот
+  sessions                      список сессий записи
+  files <session>               файлы сессии
+  download <session> <файл> [-o файл]
+                                скачать файл сессии
+
+<bot> - ID бота, префикс ID, имя бота или комната.
+
+Параметры подключения берутся из флагов, затем из переменных окружения
+SSJITSI_SERVER, SSJITSI_USER, SSJITSI_PASSWORD, затем из файла конфигурации.
+
+Флаги:
+`
+
+// Читаем параметры подключения и выполняем команду.
+func main() {
+	defaultConfig := os.Getenv("SSJITSI_CTL_CONFIG")
+	if defaultConfig == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			defaultConfig = filepath.Join(dir, "ssjitsi-ctl.yaml")
+		}
+	}
+
+	configFile := flag.String("config", defaultConfig, "Путь к файлу конфигурации клиента")
+	server := flag.String("server", "", "Адрес сервера (по умолчанию http://localhost:8080)")
+	username := flag.String("user", "", "Логин BasicAuth")
+	password := flag.String("password", "", "Пароль BasicAuth")
+	asJSON := flag.Bool("json", false, "Вывод в формате JSON")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var cfg CtlConfig
+	if *configFile != "" {
+		if data, err := os.ReadFile(*configFile); err == nil {
+			if err := yaml.Unmarshal(data, &cfg); err != nil {
+				log.Fatalf("Ошибка чтения конфигурации %s: %v", *configFile, err)
+			}
+		}
+	}
+	cfg.Server = firstNonEmpty(*server, os.Getenv("SSJITSI_SERVER"), cfg.Server, "http://localhost:8080")
+	cfg.Username = firstNonEmpty(*username, os.Getenv("SSJITSI_USER"), cfg.Username)
+	cfg.Password = firstNonEmpty(*password, os.Getenv("SSJITSI_PASSWORD"), cfg.Password)
+
+	c := NewClient(cfg.Server, cfg.Username, cfg.Password)
+	out := &output{json: *asJSON, w: os.Stdout}
+
+	if err := run(c, out, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		os.Exit(1)
+	}
+}
+
+func run(c *Client, out *output, cmd string, args []string) error {
+	switch cmd {
+	case "bots":
+		bots, err := c.Bots()
+		if err != nil {
+			return err
+		}
+		return out.table(bots, []string{"ID", "NAME", "ROOM", "AUTH", "STATUS", "ERROR"}, func(row func(...interface{})) {
+			for _, b := range bots {
+				status := b.Status
+				if b.QueuePos > 0 {
+					status = fmt.Sprintf("%s #%d", status, b.QueuePos)
+				}
+				row(b.ID, b.BotName, b.Room, b.AuthMethod, status, b.Error)
+			}
+		})
+
+	case "start", "stop", "restart":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: %s <bot>", cmd)
+		}
+		id, err := c.ResolveBot(args[0])
+		if err != nil {
+			return err
+		}
+		res, err := c.Action(id, cmd)
+		if err != nil {
+			return err
+		}
+		if out.json {
+			return out.print(res)
+		}
+		fmt.Fprintf(out.w, "%s: %v (status: %v)\n", id, res["message"], res["status"])
+		return nil
+
+	case "events":
+		fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+		follow := fs.Bool("f", false, "Следить за новыми событиями")
+		interval := fs.Duration("interval", 2*time.Second, "Интервал опроса")
+		pos, err := parseArgs(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(pos) != 1 {
+			return fmt.Errorf("usage: events <bot> [-f]")
+		}
+		id, err := c.ResolveBot(pos[0])
+		if err != nil {
+			return err
+		}
+		return out.tail(*follow, *interval, func(since int64) ([]eventLine, error) {
+			events, err := c.Events(id, since)
+			return toLines(events, true), err
+		})
+
+	case "logs":
+		fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+		follow := fs.Bool("f", false, "Следить за новыми строками")
+		interval := fs.Duration("interval", 2*time.Second, "Интервал опроса")
+		pos, err := parseArgs(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(pos) != 0 {
+			return fmt.Errorf("usage: logs [-f]")
+		}
+		return out.tail(*follow, *interval, func(since int64) ([]eventLine, error) {
+			lines, err := c.Logs(since)
+			return toLines(lines, false), err
+		})
+
+	case "screenshot":
+		fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+		file := fs.String("o", "", "Файл для сохранения (по умолчанию <id>.png)")
+		pos, err := parseArgs(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(pos) != 1 {
+			return fmt.Errorf("usage: screenshot <bot> [-o file]")
+		}
+		id, err := c.ResolveBot(pos[0])
+		if err != nil {
+			return err
+		}
+		if *file == "" {
+			*file = id + ".png"
+		}
+		return saveTo(*file, func(w io.Writer) error { return c.Screenshot(id, w) })
+
+	case "sessions":
+		sessions, err := c.Sessions()
+		if err != nil {
+			return err
+		}
+		return out.table(sessions, []string{"ID", "ROOM", "FILES", "SIZE", "MODIFIED"}, func(row func(...interface{})) {
+			for _, s := range sessions {
+				row(s.ID, s.Room, s.Files, s.Size, s.Modified.Format(time.DateTime))
+			}
+		})
+
+	case "files":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: files <session>")
+		}
+		files, err := c.SessionFiles(args[0])
+		if err != nil {
+			return err
+		}
+		return out.table(files, []string{"NAME", "SIZE", "MODIFIED"}, func(row func(...interface{})) {
+			for _, f := range files {
+				row(f.Name, f.Size, f.Modified.Format(time.DateTime))
+			}
+		})
+
+	case "download":
+		fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+		file := fs.String("o", "", "Файл для сохранения (по умолчанию имя файла сессии)")
+		pos, err := parseArgs(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(pos) != 2 {
+			return fmt.Errorf("usage: download <session> <file> [-o file]")
+		}
+		if *file == "" {
+			*file = path.Base(pos[1])
+		}
+		return saveTo(*file, func(w io.Writer) error { return c.DownloadFile(pos[0], pos[1], w) })
+
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// parseArgs разбирает флаги команды до и после позиционных аргументов:
+// flag.FlagSet.Parse останавливается на первом из них, поэтому разбор
+// повторяется для остатка. После "--" все аргументы позиционные.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(pos, rest...), nil
+		}
+		if len(rest) == 0 {
+			break
+		}
+		pos = append(pos, rest[0])
+		args = rest[1:]
+	}
+	return pos, nil
+}
+
+// output печатает результат таблицей или в JSON
+type output struct {
+	json bool
+	w    io.Writer
+}
+
+func (o *output) print(v interface{}) error {
+	enc := json.NewEncoder(o.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (o *output) table(v interface{}, header []string, rows func(row func(...interface{}))) error {
+	if o.json {
+		return o.print(v)
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 4, 2, ' ', 0)
+	for i, h := range header {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, h)
+	}
+	fmt.Fprintln(tw)
+	rows(func(cols ...interface{}) {
+		for i, col := range cols {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, col)
+		}
+		fmt.Fprintln(tw)
+	})
+	return tw.Flush()
+}
+
+// eventLine - строка журнала или события для вывода
+type eventLine struct {
+	event ssjitsi.Event
+	text  string
+}
+
+// toLines форматирует события; для журнала сервера время уже есть в строке
+func toLines(events []ssjitsi.Event, withTime bool) []eventLine {
+	lines := make([]eventLine, 0, len(events))
+	for _, ev := range events {
+		text := ev.Message
+		if withTime {
+			text = fmt.Sprintf("%s  %-8s %s", ev.Time.Format(time.DateTime), ev.Type, ev.Message)
+		}
+		lines = append(lines, eventLine{event: ev, text: text})
+	}
+	return lines
+}
+
+// tail выводит события и при follow продолжает опрашивать сервер
+func (o *output) tail(follow bool, interval time.Duration, fetch func(since int64) ([]eventLine, error)) error {
+	var since int64
+	for {
+		lines, err := fetch(since)
+		if err != nil {
+			if !follow {
+				return err
+			}
+			// В режиме слежения временная ошибка не прерывает вывод
+			fmt.Fprintln(os.Stderr, "Ошибка:", err)
+			time.Sleep(interval)
+			continue
+		}
+		for _, l := range lines {
+			if o.json {
+				if err := json.NewEncoder(o.w).Encode(l.event); err != nil {
+					return err
+				}
+			} else {
+				fmt.Fprintln(o.w, l.text)
+			}
+			since = l.event.Seq
+		}
+		if !follow {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+// saveTo записывает результат загрузки в файл
+func saveTo(file string, fetch func(w io.Writer) error) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := fetch(f); err != nil {
+		f.Close()
+		os.Remove(file)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Сохранено:", file)
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
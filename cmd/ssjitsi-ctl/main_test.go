@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		pos     string
+		file    string
+		follow  bool
+		wantErr bool
+	}{
+		{"no args", nil, "", "", false, false},
+		{"positionals only", []string{"s1", "a.webm"}, "s1 a.webm", "", false, false},
+		{"flags first", []string{"-o", "out.webm", "-f", "s1", "a.webm"}, "s1 a.webm", "out.webm", true, false},
+		{"flags last", []string{"s1", "a.webm", "-o", "out.webm"}, "s1 a.webm", "out.webm", false, false},
+		{"flags between", []string{"s1", "-f", "a.webm", "-o=out.webm"}, "s1 a.webm", "out.webm", true, false},
+		{"double dash ends flags", []string{"-f", "--", "s1", "-o"}, "s1 -o", "", true, false},
+		{"double dash after positional", []string{"s1", "--", "-f"}, "s1 -f", "", false, false},
+		{"unknown flag", []string{"s1", "-x"}, "", "", false, true},
+		{"missing flag value", []string{"s1", "-o"}, "", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			file := fs.String("o", "", "")
+			follow := fs.Bool("f", false, "")
+
+			pos, err := parseArgs(fs, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseArgs(%q) = %q, want error", tt.args, pos)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(pos, " "); got != tt.pos {
+				t.Errorf("positionals = %q, want %q", got, tt.pos)
+			}
+			if *file != tt.file || *follow != tt.follow {
+				t.Errorf("-o = %q, -f = %v; want %q, %v", *file, *follow, tt.file, tt.follow)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package ssjitsi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// Максимальный размер загружаемого клипа
+const maxAnnounceSize = 16 << 20
+
+var (
+	ErrAnnounceDisabled = errors.New("announcements are not enabled for this bot")
+	ErrAnnounceBusy     = errors.New("another announcement is playing")
+	ErrClipNotFound     = errors.New("announcement clip not found")
+	ErrNotWAV           = errors.New("announcement clip is not a WAV file")
+)
+
+// AnnounceOptions включает воспроизведение звука в конференцию. Вместо
+// микрофона страница получает поток AudioContext, в который проигрываются
+// WAV клипы; бот включает микрофон только на время клипа.
+type AnnounceOptions struct {
+	Enabled bool              `yaml:"Enabled"` // Подменять микрофон даже без File и Clips (для загружаемых клипов)
+	File    string            `yaml:"File"`    // WAV, проигрываемый после входа в конференцию
+	Clips   map[string]string `yaml:"Clips"`   // Именованные клипы для POST /:id/announce?clip=имя
+}
+
+// enabled сообщает, нужно ли подменять микрофон
+func (o AnnounceOptions) enabled() bool {
+	return o.Enabled || o.File != "" || len(o.Clips) > 0
+}
+
+// validate проверяет, что файлы клипов доступны и являются WAV
+func (o AnnounceOptions) validate() error {
+	if o.File != "" {
+		if err := checkWAVFile(o.File); err != nil {
+			return fmt.Errorf("Announcement.File: %v", err)
+		}
+	}
+	for name, path := range o.Clips {
+		if err := checkWAVFile(path); err != nil {
+			return fmt.Errorf("Announcement.Clips[%s]: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// checkWAV проверяет заголовок RIFF/WAVE; остальное разбирает браузер
+func checkWAV(data []byte) error {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return ErrNotWAV
+	}
+	return nil
+}
+
+// checkWAVFile проверяет заголовок файла клипа
+func checkWAVFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	header := make([]byte, 12)
+	n, _ := io.ReadFull(f, header)
+	return checkWAV(header[:n])
+}
+
+// announcerScript подменяет getUserMedia до загрузки Jitsi Meet: аудио
+// берется из MediaStreamDestination, куда ssbot_announcer.play проигрывает
+// клипы. Видео, если его запросят, получается как обычно.
+const announcerScript = `(function () {
+	if (window.top !== window || !navigator.mediaDevices || window.ssbot_announcer) {
+		return;
+	}
+	const md = navigator.mediaDevices;
+	const getUserMedia = md.getUserMedia.bind(md);
+	const enumerateDevices = md.enumerateDevices.bind(md);
+	let ctx = null;
+	let dest = null;
+	let playing = false;
+
+	function destination() {
+		if (!ctx) {
+			ctx = new AudioContext();
+			dest = ctx.createMediaStreamDestination();
+		}
+		return dest;
+	}
+
+	md.getUserMedia = async function (constraints) {
+		if (!constraints || !constraints.audio) {
+			return getUserMedia(constraints);
+		}
+		// Jitsi останавливает дорожку при освобождении, поэтому отдаем копию
+		const tracks = [destination().stream.getAudioTracks()[0].clone()];
+		if (constraints.video) {
+			const video = await getUserMedia({ video: constraints.video });
+			tracks.push(...video.getVideoTracks());
+		}
+		return new MediaStream(tracks);
+	};
+
+	md.enumerateDevices = async function () {
+		const list = await enumerateDevices();
+		if (!list.some((d) => d.kind === 'audioinput' && d.deviceId)) {
+			list.push({ deviceId: 'ssbot-announcer', groupId: 'ssbot', kind: 'audioinput', label: 'ssbot announcer', toJSON() { return this; } });
+		}
+		return list;
+	};
+
+	window.ssbot_announcer = {
+		// Возвращает длительность клипа в секундах или -1, если играет другой
+		async play(b64) {
+			if (playing) {
+				return -1;
+			}
+			playing = true;
+			try {
+				destination();
+				await ctx.resume();
+				const bytes = Uint8Array.from(atob(b64), (c) => c.charCodeAt(0));
+				const buffer = await ctx.decodeAudioData(bytes.buffer);
+				const conf = window.APP && APP.conference;
+				if (conf && conf.muteAudio) {
+					conf.muteAudio(false);
+				}
+				const src = ctx.createBufferSource();
+				src.buffer = buffer;
+				src.connect(dest);
+				src.onended = () => {
+					playing = false;
+					if (conf && conf.muteAudio) {
+						conf.muteAudio(true);
+					}
+				};
+				// Пауза, чтобы начало клипа не потерялось при включении микрофона
+				src.start(ctx.currentTime + 0.5);
+				return buffer.duration;
+			} catch (e) {
+				playing = false;
+				throw e;
+			}
+		}
+	};
+})();`
+
+// registerAnnouncer подменяет микрофон на каждом новом документе вкладки;
+// должен выполняться до открытия страницы встречи
+func (bot *Bot) registerAnnouncer(ctx context.Context) error {
+	if !bot.Announcement.enabled() {
+		return nil
+	}
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(announcerScript).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to register announcer: %v", err)
+		}
+		return nil
+	}))
+}
+
+// announceClip возвращает содержимое именованного клипа; пустое имя -
+// клип, проигрываемый при входе
+func (bot *Bot) announceClip(name string) ([]byte, error) {
+	path := bot.Announcement.File
+	if name != "" {
+		path = bot.Announcement.Clips[name]
+	}
+	if path == "" {
+		return nil, ErrClipNotFound
+	}
+	return os.ReadFile(path)
+}
+
+// Announce проигрывает WAV клип в конференцию и возвращает его длительность
+// в секундах. Возврат происходит сразу после начала воспроизведения.
+func (bot *Bot) Announce(ctx context.Context, data []byte) (float64, error) {
+	if !bot.Announcement.enabled() {
+		return 0, ErrAnnounceDisabled
+	}
+	bot.mu.RLock()
+	tab := bot.Ctx
+	bot.mu.RUnlock()
+	if tab == nil {
+		return 0, errors.New("bot is not running")
+	}
+	// Декодирование клипа ограничивается и контекстом вызывающего, и временем жизни вкладки
+	tab, cancel := context.WithCancel(tab)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	var duration float64
+	expr := fmt.Sprintf("window.ssbot_announcer ? ssbot_announcer.play(%q) : -2", base64.StdEncoding.EncodeToString(data))
+	err := chromedp.Run(tab, chromedp.Evaluate(expr, &duration, evalAwait, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		// AudioContext запускается только по жесту пользователя
+		return p.WithUserGesture(true)
+	}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to play announcement: %v", err)
+	}
+	switch duration {
+	case -1:
+		return 0, ErrAnnounceBusy
+	case -2:
+		return 0, errors.New("announcer is not installed on the page")
+	}
+
+	log.Printf("Бот %s: объявление %.1f с", bot.ID, duration)
+	bot.Events.Add("info", fmt.Sprintf("announcement played (%.1fs)", duration))
+	return duration, nil
+}
+
+// announceOnJoin проигрывает клип File после входа; ошибка не прерывает запись
+func (bot *Bot) announceOnJoin() {
+	if bot.Announcement.File == "" {
+		return
+	}
+	data, err := bot.announceClip("")
+	if err == nil {
+		_, err = bot.Announce(context.Background(), data)
+	}
+	if err != nil {
+		log.Printf("Бот %s: не удалось проиграть объявление: %v", bot.ID, err)
+		bot.Events.Add("error", fmt.Sprintf("join announcement failed: %v", err))
+	}
+}
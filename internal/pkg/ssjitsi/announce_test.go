@@ -0,0 +1,27 @@
+package ssjitsi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckWAV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"wav", "RIFF\x24\x00\x00\x00WAVEfmt ", nil},
+		{"empty", "", ErrNotWAV},
+		{"short", "RIFF", ErrNotWAV},
+		{"riff not wave", "RIFF\x24\x00\x00\x00AVI LIST", ErrNotWAV},
+		{"mp3", "ID3\x03\x00\x00\x00\x00\x00\x00\x00\x00", ErrNotWAV},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkWAV([]byte(tt.data)); !errors.Is(err, tt.err) {
+				t.Errorf("checkWAV() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
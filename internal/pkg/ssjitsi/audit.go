@@ -0,0 +1,80 @@
+package ssjitsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Имя журнала аудита в DataDir бота, если audit_log не задан
+const defaultAuditFile = "audit.jsonl"
+
+// AuditEntry - запись журнала аудита о действии, выполненном ботом по запросу
+type AuditEntry struct {
+	Time    time.Time              `json:"time"`
+	BotID   string                 `json:"botId"`
+	BotName string                 `json:"botName"`
+	Room    string                 `json:"room"`
+	Action  string                 `json:"action"`
+	Args    map[string]interface{} `json:"args,omitempty"`
+	Actor   string                 `json:"actor,omitempty"`  // Пользователь BasicAuth, если есть
+	Client  string                 `json:"client,omitempty"` // Адрес клиента API
+	Result  string                 `json:"result"`           // ok, denied или error
+	Error   string                 `json:"error,omitempty"`
+}
+
+// Журнал может быть общим для нескольких ботов
+var auditMu sync.Mutex
+
+// auditPath возвращает путь журнала аудита бота
+func (bot *Bot) auditPath() string {
+	if bot.auditLog != "" {
+		return bot.auditLog
+	}
+	return filepath.Join(bot.DataDir, defaultAuditFile)
+}
+
+// audit дописывает запись в журнал аудита и в события бота
+func (bot *Bot) audit(entry AuditEntry) {
+	entry.Time = time.Now()
+	entry.BotID, entry.BotName, entry.Room = bot.ID, bot.BotName, bot.Room
+
+	msg := fmt.Sprintf("%s by %s: %s", entry.Action, entry.Actor, entry.Result)
+	if entry.Actor == "" {
+		msg = fmt.Sprintf("%s: %s", entry.Action, entry.Result)
+	}
+	if entry.Error != "" {
+		msg += " (" + entry.Error + ")"
+	}
+	bot.Events.Add("audit", msg)
+	log.Printf("Аудит, бот %s: %s", bot.ID, msg)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	path := bot.auditPath()
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("Не удалось записать журнал аудита: %v", err)
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		log.Printf("Не удалось записать журнал аудита: %v", err)
+		return
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Printf("Не удалось записать журнал аудита: %v", err)
+	}
+}
@@ -1,42 +1,91 @@
 package ssjitsi
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/chromedp/cdproto/browser"
-	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
-	"github.com/golang-jwt/jwt/v5"
+)
+
+// Статусы бота
+const (
+	StatusStopped       = "stopped"
+	StatusStarting      = "starting"
+	StatusRunning       = "running"
+	StatusStopping      = "stopping"
+	StatusWrongPassword = "wrong_password" // Неверный или отсутствующий пароль комнаты
+	StatusInLobby       = "in_lobby"       // Ожидает допуска в лобби
+	StatusQueued        = "queued"         // Ожидает своей очереди на запуск
 )
 
 type Bot struct {
-	ID            string             `yaml:"ID,omitempty"`
-	Room          string             `yaml:"Room"`
-	BotName       string             `yaml:"BotName"`
-	DataDir       string             `yaml:"DataDir"`
-	JitsiServer   string             `yaml:"JitsiServer"`
-	Username      string             `yaml:"Username"`
-	Pass          string             `yaml:"Pass"`
-	JWTAppID      string             `yaml:"JWTAppID"`
-	JWTAppSecret  string             `yaml:"JWTAppSecret"`
-	Headless      bool               `yaml:"Headless"`
-	Ctx           context.Context    `yaml:"-"`
-	CtxCancel     context.CancelFunc `yaml:"-"`
-	AllocCancel   context.CancelFunc `yaml:"-"` // Cancel для allocator контекста
-	Status        string             `yaml:"-"` // Статус бота: "running", "stopped", "starting", "stopping"
-	mu            sync.RWMutex       `yaml:"-"` // Mutex для потокобезопасности
+	ID           string              `yaml:"ID,omitempty"`
+	Room         string              `yaml:"Room"`
+	BotName      string              `yaml:"BotName"`
+	DataDir      string              `yaml:"DataDir"`
+	JitsiServer  string              `yaml:"JitsiServer"`
+	Username     string              `yaml:"Username"`
+	Pass         string              `yaml:"Pass"`
+	JWTAppID     string              `yaml:"JWTAppID"`
+	JWTAppSecret string              `yaml:"JWTAppSecret"`
+	JWT          JWTOptions          `yaml:"JWT"`          // Дополнительные параметры JWT токена
+	TokenService TokenServiceOptions `yaml:"TokenService"` // Внешний сервис выдачи токенов
+	Headless     bool                `yaml:"Headless"`
+	Script       string              `yaml:"Script"`       // Путь к своему скрипту записи вместо встроенного
+	Plugins      []string            `yaml:"Plugins"`      // Пути к JS плагинам, внедряемым после скрипта записи
+	Recording    RecordingOptions    `yaml:"Recording"`    // Параметры MediaRecorder
+	Chrome       ChromeOptions       `yaml:"Chrome"`       // Параметры запуска браузера, перекрывают глобальную секцию chrome
+	Screencast   ScreencastOptions   `yaml:"Screencast"`   // Запись всей страницы встречи через CDP screencast
+	Timelapse    TimelapseOptions    `yaml:"Timelapse"`    // Периодические снимки страницы в каталог сессии
+	Announcement AnnounceOptions     `yaml:"Announcement"` // Воспроизведение звука в конференцию
+	Stats        StatsOptions        `yaml:"Stats"`        // Статистика WebRTC по входящему аудио
+
+	RoomPassword   string        `yaml:"RoomPassword"`   // Пароль комнаты, если модератор её закрыл
+	E2EEPassphrase string        `yaml:"E2EEPassphrase"` // Парольная фраза сквозного шифрования
+	JoinTimeout    time.Duration `yaml:"JoinTimeout"`    // Таймаут входа в конференцию (по умолчанию 60s)
+	LobbyTimeout   time.Duration `yaml:"LobbyTimeout"`   // Время ожидания допуска из лобби (по умолчанию 5m)
+	JoinProfile    string        `yaml:"JoinProfile"`    // Сценарий входа: jwt, form или из join_profiles
+	JoinTrace      bool          `yaml:"JoinTrace"`      // Писать в лог каждый шаг сценария входа
+	JoinMessage    string        `yaml:"JoinMessage"`    // Сообщение в чат после входа (шаблон как в сценарии входа)
+	LeaveMessage   string        `yaml:"LeaveMessage"`   // Сообщение в чат перед остановкой
+
+	ConfigOverrides          map[string]interface{} `yaml:"ConfigOverrides"`          // Параметры config.* во фрагменте адреса комнаты
+	InterfaceConfigOverrides map[string]interface{} `yaml:"InterfaceConfigOverrides"` // Параметры interfaceConfig.* во фрагменте адреса
+
+	Ctx          context.Context       `yaml:"-"`
+	CtxCancel    context.CancelFunc    `yaml:"-"`
+	AllocCancel  context.CancelFunc    `yaml:"-"` // Cancel для allocator контекста
+	Status       string                `yaml:"-"` // Статус бота: Status* константы
+	Events       EventLog              `yaml:"-"` // Журнал событий бота
+	lastError    string                `yaml:"-"` // Последняя ошибка запуска
+	joinProfiles map[string][]JoinStep `yaml:"-"` // Сценарии входа из конфигурации
+	pool         *BrowserPool          `yaml:"-"` // Общий пул браузеров, если включен
+	launcher     *Launcher             `yaml:"-"` // Очередь запуска, если заданы ограничения
+	uploader     *ChunkServer          `yaml:"-"` // Приемник чанков записи по HTTP
+	scriptInfo   ScriptInfo            `yaml:"-"` // Версия и возможности внедренного скрипта
+	scripts      []pageScript          `yaml:"-"` // Внедряемые скрипты текущего запуска
+	segment      int                   `yaml:"-"` // Текущий сегмент записи
+	segments     int                   `yaml:"-"` // Сколько сегментов начато в этой сессии
+	rejoining    bool                  `yaml:"-"` // Идет восстановление после перезагрузки
+	trackExt     string                `yaml:"-"` // Расширение файлов дорожек по фактическому MIME типу
+	sessionDir   string                `yaml:"-"` // Каталог текущей сессии записи
+	lease        *browserLease         `yaml:"-"` // Место бота в общем браузере
+	auditLog     string                `yaml:"-"` // Путь журнала аудита; пусто - {DataDir}/audit.jsonl
+	lastStats    *StatsSample          `yaml:"-"` // Последний замер качества соединения
+	mu           sync.RWMutex          `yaml:"-"` // Mutex для потокобезопасности
 }
 type Record struct {
 	U      string `json:"u"`
@@ -45,43 +94,10 @@ type Record struct {
 	UserId string `json:"userid"`
 	Room   string `json:"room"`
 	Myid   string `json:"myid"`
-}
+	Kind   string `json:"kind"`   // audio или video
+	Source string `json:"source"` // microphone, camera, desktop, mix или screen
 
-// GenerateJitsiJWT генерирует JWT токен для авторизации в Jitsi Meet
-func GenerateJitsiJWT(appID, appSecret, jitsiServer, room, userName string) (string, error) {
-	// Извлекаем домен из URL сервера Jitsi
-	parsedURL, err := url.Parse(jitsiServer)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse Jitsi server URL: %v", err)
-	}
-	domain := parsedURL.Hostname()
-
-	// Создаем claims для токена
-	now := time.Now()
-	claims := jwt.MapClaims{
-		"iss":  appID,                         // Issuer - идентификатор приложения
-		"aud":  appID,                         // Audience - идентификатор приложения
-		"sub":  domain,                        // Subject - домен Jitsi сервера
-		"room": room,                          // Имя комнаты
-		"exp":  now.Add(2 * time.Hour).Unix(), // Expiration - токен действителен 2 часа
-		"nbf":  now.Unix(),                    // Not Before - токен действителен с текущего момента
-		"context": map[string]interface{}{
-			"user": map[string]interface{}{
-				"name": userName,
-			},
-		},
-	}
-
-	// Создаем токен с алгоритмом HS256
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Подписываем токен секретным ключом
-	tokenString, err := token.SignedString([]byte(appSecret))
-	if err != nil {
-		return "", fmt.Errorf("failed to sign JWT token: %v", err)
-	}
-
-	return tokenString, nil
+	Levels *LevelSamples `json:"levels,omitempty"` // Замеры громкости за время чанка
 }
 
 // GetStatus возвращает текущий статус бота (потокобезопасно)
@@ -94,34 +110,34 @@ func (bot *Bot) GetStatus() string {
 // SetStatus устанавливает статус бота (потокобезопасно)
 func (bot *Bot) SetStatus(status string) {
 	bot.mu.Lock()
-	defer bot.mu.Unlock()
+	changed := bot.Status != status
 	bot.Status = status
+	bot.mu.Unlock()
+
+	if changed {
+		bot.Events.Add("status", status)
+	}
 }
 
 func (bot *Bot) Start() error {
-	bot.SetStatus("starting")
-
-	ctx, baseCancel := chromedp.NewContext(context.Background())
+	bot.SetStatus(StatusStarting)
+	bot.SetError(nil)
 
-	jsContent, err := os.ReadFile("script.js")
+	scripts, err := bot.loadScripts()
 	if err != nil {
-		fmt.Println(err)
-		bot.SetStatus("stopped")
-		return err
+		return bot.fail(err)
 	}
 
-	allocCtx, allocCancel := chromedp.NewExecAllocator(
-		ctx,
-		append(chromedp.DefaultExecAllocatorOptions[:],
-			chromedp.Flag("use-fake-ui-for-media-stream", true),
-			chromedp.Flag("headless", bot.Headless),
-		)...,
-	)
+	// Ждем очереди, если запуск ботов ограничен
+	slot, err := bot.waitLaunchSlot()
+	if err != nil {
+		return bot.fail(err)
+	}
+	defer slot.done()
 
-	bot.Ctx, bot.CtxCancel = chromedp.NewContext(allocCtx)
-	bot.AllocCancel = func() {
-		baseCancel()
-		allocCancel()
+	// Открываем вкладку в собственном или общем браузере
+	if err := bot.newBrowserContext(); err != nil {
+		return bot.fail(err)
 	}
 
 	chromedp.ListenTarget(bot.Ctx, func(ev interface{}) {
@@ -143,113 +159,125 @@ func (bot *Bot) Start() error {
 					fmt.Println("Error unmarshaling JSON:", err)
 					return
 				}
-				writeRecordToFile(p, bot.DataDir, bot.ID)
+				bot.writeRecordToFile(p)
 			}
 		case *browser.EventDownloadProgress:
 			if ev.State == browser.DownloadProgressStateCompleted {
 				log.Println(ev.GUID)
 			}
+		case *page.EventFrameNavigated:
+			// Новый документ в главном фрейме - страница перезагружена
+			if ev.Frame.ParentID == "" {
+				bot.onNavigated()
+			}
 		case *runtime.EventExceptionThrown:
 			fmt.Printf("Exception thrown: %s\n", ev.ExceptionDetails.Text)
 		}
 	})
 
-	params := &browser.SetPermissionParams{
-		Permission: &browser.PermissionDescriptor{
-			Name: "microphone",
-		},
-		Setting: browser.PermissionSettingDenied, //browser.PermissionSettingGranted,
+	// Микрофон подменяется до загрузки страницы встречи
+	if err := bot.registerAnnouncer(bot.Ctx); err != nil {
+		return bot.fail(err)
 	}
 
-	// run task list
-	var res string
-	// var buf []byte
+	// Проверяем, нужна ли авторизация по токену
+	authMethod := getAuthMethod(bot)
+	var token string
+	var tokenExpiresAt time.Time
+	if authMethod == "JWT" || authMethod == "TokenService" {
+		log.Printf("Используем авторизацию по токену (%s)", authMethod)
 
-	// Проверяем, нужна ли JWT авторизация
-	if bot.JWTAppID != "" && bot.JWTAppSecret != "" {
-		// Используем JWT авторизацию
-		log.Println("Используем JWT авторизацию")
-
-		// Генерируем JWT токен
-		token, err := GenerateJitsiJWT(bot.JWTAppID, bot.JWTAppSecret, bot.JitsiServer, bot.Room, bot.BotName)
+		// Получаем токен; при каждом входе выпускается новый
+		token, tokenExpiresAt, err = bot.meetingToken()
 		if err != nil {
-			return fmt.Errorf("failed to generate JWT token: %v", err)
+			return bot.fail(fmt.Errorf("failed to get meeting token: %v", err))
 		}
+	}
 
-		// Формируем URL с JWT токеном
-		jitsiURL := strings.TrimRight(bot.JitsiServer, "/") + "/" + bot.Room + "?jwt=" + token
-		log.Printf("Переходим на URL: %s", strings.TrimRight(bot.JitsiServer, "/")+"/"+bot.Room+"?jwt=***")
-
-		err = chromedp.Run(bot.Ctx,
-			chromedp.Navigate(jitsiURL),
-			chromedp.ActionFunc(func(ctx context.Context) error {
-				// Параметры: разрешение, настройка, источник (опционально)
-				return params.Do(ctx)
-			}),
-			chromedp.Sleep(2*time.Second), // Даем время на загрузку страницы
-			chromedp.Click(`[aria-label="Join meeting"]`, chromedp.ByQuery),
-			chromedp.Sleep(2*time.Second), // Даем время на подключение к конференции
-		)
-		if err != nil {
-			return err
-		}
-	} else {
-		// Используем старый метод с формами
-		log.Println("Используем авторизацию с формами")
-
-		var nodes []*cdp.Node
-		err = chromedp.Run(bot.Ctx,
-			chromedp.Navigate(bot.JitsiServer),
-			chromedp.Click(`[aria-label="Meeting name input"]`, chromedp.ByQuery),
-			chromedp.SendKeys(`[aria-label="Meeting name input"]`, bot.Room, chromedp.ByQuery),
-			chromedp.Click("#enter_room_button", chromedp.ByQuery),
-			chromedp.ActionFunc(func(ctx context.Context) error {
-				// Параметры: разрешение, настройка, источник (опционально)
-				return params.Do(ctx)
-			}),
-			chromedp.Sleep(1*time.Second),
-			chromedp.SendKeys(`[aria-label="Enter your name"]`, bot.BotName, chromedp.ByQuery),
-			chromedp.Click(`[aria-label="Join meeting"]`, chromedp.ByQuery),
-			chromedp.Click(`[aria-label="Join meeting"]`, chromedp.ByQuery),
-			chromedp.Sleep(2*time.Second),
-			chromedp.Nodes("#login-dialog-username", &nodes, chromedp.AtLeast(0)),
-		)
+	// Выполняем сценарий входа в комнату
+	err = bot.runJoinFlow(bot.Ctx, token)
+	if err != nil {
+		return bot.fail(err)
+	}
+	// Страница загружена - следующий бот может начинать запуск
+	slot.started()
+
+	// Дожидаемся входа в конференцию, при необходимости вводим пароль комнаты
+	err = bot.waitJoined(bot.Ctx)
+	if err != nil {
+		return bot.fail(err)
+	}
+	// Каталог сессии известен до внедрения скрипта, иначе дорожки первых
+	// чанков не попадут в манифест
+	bot.resolveSessionDir()
+
+	if bot.E2EEPassphrase != "" {
+		err = bot.enableE2EE(bot.Ctx)
 		if err != nil {
-			return err
+			return bot.fail(err)
 		}
+	}
 
-		// Нужна авторизация?
-		if len(nodes) > 0 {
-			log.Println("Авторизуемся.")
-			err = chromedp.Run(bot.Ctx,
-				chromedp.SendKeys("#login-dialog-username", bot.Username, chromedp.ByQuery),
-				chromedp.SendKeys("#login-dialog-password", bot.Pass, chromedp.ByQuery),
-				chromedp.Click(`[aria-label="Login"]`, chromedp.ByQuery),
-				chromedp.Sleep(1*time.Second),
-			)
-			if err != nil {
-				return err
-			}
-		}
+	err = chromedp.Run(bot.Ctx, runtime.AddBinding("ssbot_writeSound"))
+	if err != nil {
+		return bot.fail(err)
 	}
 
-	err = chromedp.Run(bot.Ctx,
-		runtime.AddBinding("ssbot_writeSound"),
-		chromedp.Evaluate(string(jsContent), &res),
-	)
+	// Параметры записи и приемник чанков передаются странице первым скриптом
+	config, err := bot.configScript()
+	if err != nil {
+		return bot.fail(err)
+	}
+	scripts = append([]pageScript{config}, scripts...)
 
+	// Внедряем скрипт записи и плагины, проверяем версию протокола
+	bot.scripts = scripts
+	bot.nextSegment()
+	err = bot.injectScripts(scripts)
 	if err != nil {
-		bot.SetStatus("stopped")
-		return err
+		return bot.fail(err)
+	}
+
+	// Скрипты будут выполняться и на новых документах после перезагрузки
+	err = bot.registerScripts(bot.Ctx, scripts)
+	if err != nil {
+		return bot.fail(err)
 	}
 
-	bot.SetStatus("running")
+	// Проверяем параметры записи и сохраняем манифест сессии
+	probe, err := bot.checkRecording()
+	if err != nil {
+		return bot.fail(err)
+	}
+	err = bot.writeStartManifest(probe)
+	if err != nil {
+		log.Printf("Бот %s: не удалось записать манифест: %v", bot.ID, err)
+	}
+	err = bot.startScreencast(bot.Ctx)
+	if err != nil {
+		return bot.fail(err)
+	}
+
+	bot.SetStatus(StatusRunning)
 	log.Printf("Бот %s (%s) запущен и работает", bot.BotName, bot.ID)
 
+	if !tokenExpiresAt.IsZero() {
+		go bot.refreshToken(bot.Ctx, tokenExpiresAt)
+	}
+	go bot.runTimelapse(bot.Ctx)
+	go bot.runStats(bot.Ctx)
+	go bot.announceOnJoin()
+	go bot.sendConfiguredMessage(bot.Ctx, "join message", bot.JoinMessage)
+
 	// Блокируемся, пока контекст не будет отменен
 	<-bot.Ctx.Done()
 
-	bot.SetStatus("stopped")
+	// Упавший общий браузер - переносим бота в другой процесс
+	if bot.migrateIfBrowserLost() {
+		return nil
+	}
+
+	bot.SetStatus(StatusStopped)
 	log.Printf("Бот %s (%s) завершил работу", bot.BotName, bot.ID)
 	return nil
 }
@@ -259,9 +287,21 @@ func (bot *Bot) Stop() error {
 	currentStatus := bot.GetStatus()
 	log.Printf("Stop() вызван для бота %s (%s), текущий статус: %s", bot.BotName, bot.ID, currentStatus)
 
-	bot.SetStatus("stopping")
+	bot.sendLeaveMessage()
+	bot.SetStatus(StatusStopping)
 
+	bot.cancelContexts()
+
+	bot.SetStatus(StatusStopped)
+	log.Printf("Бот %s (%s) остановлен, новый статус: %s", bot.BotName, bot.ID, bot.GetStatus())
+	return nil
+}
+
+// cancelContexts закрывает вкладку и браузер бота
+func (bot *Bot) cancelContexts() {
 	bot.mu.Lock()
+	defer bot.mu.Unlock()
+
 	if bot.CtxCancel != nil {
 		log.Printf("Отменяем CtxCancel для бота %s", bot.ID)
 		bot.CtxCancel()
@@ -273,11 +313,10 @@ func (bot *Bot) Stop() error {
 		bot.AllocCancel = nil
 	}
 	bot.Ctx = nil
-	bot.mu.Unlock()
-
-	bot.SetStatus("stopped")
-	log.Printf("Бот %s (%s) остановлен, новый статус: %s", bot.BotName, bot.ID, bot.GetStatus())
-	return nil
+	bot.lease = nil
+	if bot.uploader != nil {
+		bot.uploader.unregister(bot)
+	}
 }
 
 // Restart перезапускает бота
@@ -320,23 +359,40 @@ func wrf(f string, d []byte) error {
 	return nil
 }
 
-func writeRecordToFile(p Record, datadir string, sessionid string) error {
+func (bot *Bot) writeRecordToFile(p Record) error {
 	// Декодируем base64 строку
 	data, err := base64.StdEncoding.DecodeString(p.D)
 	if err != nil {
 		return fmt.Errorf("ошибка декодирования base64: %v", err)
 	}
+	return bot.writeTrackChunk(p, bytes.NewReader(data))
+}
 
-	udir := filepath.Join(datadir, SafeFilename(p.Room), sessionid)
-	err = os.MkdirAll(udir, 0755)
+// writeTrackChunk дописывает чанк дорожки участника в файл сессии
+func (bot *Bot) writeTrackChunk(p Record, data io.Reader) error {
+	udir := filepath.Join(bot.DataDir, SafeFilename(p.Room), bot.ID)
+	err := os.MkdirAll(udir, 0755)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
 
-	filename := filepath.Join(udir, p.UserId+"_"+p.U+".webm")
-	starttime := filepath.Join(udir, p.UserId+"_"+p.U+".json")
-	metadata := filepath.Join(udir, p.UserId+".json")
+	// После перезагрузки страницы дорожки пишутся в новые файлы сегмента
+	track := SafeFilename(p.UserId + "_" + p.U)
+	if p.U == roomMixTrack {
+		// Общая дорожка комнаты не принадлежит участнику
+		track = roomMixTrack
+	}
+	if segment := bot.currentSegment(); segment > 0 {
+		track += "_s" + strconv.Itoa(segment)
+	}
+	ext := bot.trackExtension()
+	if p.Kind == "video" {
+		ext = fileExtension(bot.Recording.videoMimeType())
+	}
+	filename := filepath.Join(udir, track+ext)
+	starttime := filepath.Join(udir, track+".json")
+	metadata := filepath.Join(udir, SafeFilename(p.UserId)+".json")
 	room := filepath.Join(udir, "room.json")
 
 	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
@@ -346,22 +402,38 @@ func writeRecordToFile(p Record, datadir string, sessionid string) error {
 	}
 	defer file.Close()
 
-	_, err = file.Write(data)
+	// При ошибке записи файл обрезается до прежнего размера, чтобы повтор
+	// чанка не оставил в нем обрывок
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, data)
 	if err != nil {
+		if terr := file.Truncate(info.Size()); terr != nil {
+			log.Printf("Бот %s: не удалось откатить чанк %s: %v", bot.ID, filepath.Base(filename), terr)
+		}
 		return err
 	}
 
 	_, err = os.Stat(starttime)
 	if os.IsNotExist(err) {
-		wrf(starttime, []byte(strconv.Itoa(int(time.Now().UnixMilli()))))
+		started := time.Now()
+		wrf(starttime, []byte(strconv.Itoa(int(started.UnixMilli()))))
+		bot.addManifestTrack(p, filepath.Base(filename), started)
 	}
 	_, err = os.Stat(metadata)
-	if os.IsNotExist(err) {
+	if os.IsNotExist(err) && p.U != roomMixTrack {
 		wrf(metadata, []byte(p.User))
 	}
 	_, err = os.Stat(room)
 	if os.IsNotExist(err) {
 		wrf(room, []byte(p.Room))
 	}
+	if p.Levels != nil && p.U != roomMixTrack {
+		if err := appendActivity(udir, p); err != nil {
+			log.Printf("Бот %s: не удалось записать замеры громкости: %v", bot.ID, err)
+		}
+	}
 	return nil
 }
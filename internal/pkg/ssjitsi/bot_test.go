@@ -0,0 +1,80 @@
+package ssjitsi
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWriteTrackChunk(t *testing.T) {
+	levels := &LevelSamples{T: 1000, Step: 100, V: []float64{0.1, 0.5}}
+
+	tests := []struct {
+		name      string
+		recording RecordingOptions
+		record    Record
+		want      []string // Файлы в каталоге бота, кроме манифеста; дорожка последней
+	}{
+		{
+			name:   "participant audio",
+			record: Record{U: "a1", UserId: "u1", User: "Alice", Room: "daily", Levels: levels},
+			want:   []string{activityFile, "room.json", "u1.json", "u1_a1.json", "u1_a1.webm"},
+		},
+		{
+			// Общая дорожка без метаданных участника и замеров громкости
+			name:   "room mix",
+			record: Record{U: roomMixTrack, UserId: "bot", User: "Recorder", Room: "daily", Source: "mix", Levels: levels},
+			want:   []string{"room.json", "room_mix.json", "room_mix.webm"},
+		},
+		{
+			// Видео пишется в webm независимо от контейнера аудио
+			name:      "participant camera",
+			recording: RecordingOptions{MimeType: "audio/ogg", Video: true},
+			record:    Record{U: "v1", UserId: "u1", User: "Alice", Room: "daily", Kind: "video", Source: "camera"},
+			want:      []string{"room.json", "u1.json", "u1_v1.json", "u1_v1.webm"},
+		},
+		{
+			name:      "participant audio in ogg",
+			recording: RecordingOptions{MimeType: "audio/ogg", Video: true},
+			record:    Record{U: "a1", UserId: "u1", User: "Alice", Room: "daily", Kind: "audio"},
+			want:      []string{"room.json", "u1.json", "u1_a1.json", "u1_a1.ogg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{ID: "bot", DataDir: t.TempDir(), Recording: tt.recording}
+			for _, chunk := range []string{"first", "second"} {
+				if err := bot.writeTrackChunk(tt.record, strings.NewReader(chunk)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			dir := filepath.Join(bot.DataDir, "daily", "bot")
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var files []string
+			for _, e := range entries {
+				if e.Name() != manifestFile {
+					files = append(files, e.Name())
+				}
+			}
+			sort.Strings(files)
+			if strings.Join(files, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("files = %v, want %v", files, tt.want)
+			}
+
+			track := tt.want[len(tt.want)-1]
+			data, err := os.ReadFile(filepath.Join(dir, track))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "firstsecond" {
+				t.Errorf("%s = %q, want chunks appended", track, data)
+			}
+		})
+	}
+}
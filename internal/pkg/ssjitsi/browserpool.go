@@ -0,0 +1,239 @@
+package ssjitsi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Количество ботов на один браузер по умолчанию
+const defaultMaxBotsPerBrowser = 10
+
+var ErrBrowserPoolFull = errors.New("all pooled browsers are full")
+
+// BrowserPoolOptions задает режим общего пула браузеров: каждый бот работает
+// во вкладке с изолированным контекстом браузера вместо отдельного процесса
+type BrowserPoolOptions struct {
+	Browsers          int   `yaml:"Browsers"`          // Количество процессов Chrome; 0 - пул выключен
+	MaxBotsPerBrowser int   `yaml:"MaxBotsPerBrowser"` // Ботов на один процесс (по умолчанию 10)
+	Headless          *bool `yaml:"Headless"`          // Режим без окна (по умолчанию true)
+}
+
+// enabled сообщает, включен ли пул
+func (o BrowserPoolOptions) enabled() bool {
+	return o.Browsers > 0
+}
+
+func (o BrowserPoolOptions) maxBots() int {
+	if o.MaxBotsPerBrowser > 0 {
+		return o.MaxBotsPerBrowser
+	}
+	return defaultMaxBotsPerBrowser
+}
+
+func (o BrowserPoolOptions) headless() bool {
+	return o.Headless == nil || *o.Headless
+}
+
+// BrowserPool управляет общими процессами Chrome
+type BrowserPool struct {
+	opts   BrowserPoolOptions
+	chrome ChromeOptions
+
+	mu       sync.Mutex
+	browsers []*pooledBrowser
+}
+
+// pooledBrowser - один процесс Chrome пула
+type pooledBrowser struct {
+	index       int
+	ctx         context.Context // Контекст браузера; отменяется при падении процесса
+	cancel      context.CancelFunc
+	allocCancel context.CancelFunc
+	starting    chan struct{} // Закрывается по окончании запуска процесса
+	bots        map[*Bot]struct{}
+}
+
+// alive сообщает, что процесс запущен и не упал
+func (b *pooledBrowser) alive() bool {
+	return b.ctx != nil && b.ctx.Err() == nil
+}
+
+// browserLease - место бота в пуле
+type browserLease struct {
+	pool    *BrowserPool
+	browser *pooledBrowser
+	ctx     context.Context // Контекст процесса, в котором открыта вкладка
+}
+
+// NewBrowserPool создает пул; процессы запускаются по мере необходимости
+func NewBrowserPool(opts BrowserPoolOptions, chrome ChromeOptions) *BrowserPool {
+	pool := &BrowserPool{opts: opts, chrome: chrome}
+	for i := 0; i < opts.Browsers; i++ {
+		pool.browsers = append(pool.browsers, &pooledBrowser{index: i + 1, bots: map[*Bot]struct{}{}})
+	}
+	return pool
+}
+
+// acquire выбирает наименее загруженный браузер и открывает в нем вкладку
+// с новым изолированным контекстом. Процесс запускается без блокировки
+// пула, другие боты тем временем занимают и освобождают места.
+func (p *BrowserPool) acquire(bot *Bot) (context.Context, context.CancelFunc, *browserLease, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		best := p.pick()
+		if best == nil {
+			return nil, nil, nil, ErrBrowserPoolFull
+		}
+		if wait := best.starting; wait != nil {
+			// Процесс запускает другой бот: ждем и выбираем заново
+			p.mu.Unlock()
+			<-wait
+			p.mu.Lock()
+			continue
+		}
+
+		if !best.alive() {
+			// Место занимается на время запуска, чтобы его учитывали другие боты
+			best.bots[bot] = struct{}{}
+			best.starting = make(chan struct{})
+			cancel, allocCancel := best.cancel, best.allocCancel
+			p.mu.Unlock()
+
+			if allocCancel != nil {
+				cancel()
+				allocCancel()
+			}
+			ctx, cancel, allocCancel, err := p.launch(best.index)
+
+			p.mu.Lock()
+			close(best.starting)
+			best.starting = nil
+			if err != nil {
+				delete(best.bots, bot)
+				return nil, nil, nil, err
+			}
+			best.ctx, best.cancel, best.allocCancel = ctx, cancel, allocCancel
+		}
+
+		ctx, cancel := chromedp.NewContext(best.ctx, chromedp.WithNewBrowserContext())
+		best.bots[bot] = struct{}{}
+		log.Printf("Бот %s: вкладка в общем браузере #%d (%d/%d)", bot.ID, best.index, len(best.bots), p.opts.maxBots())
+		bot.Events.Add("info", fmt.Sprintf("using pooled browser #%d", best.index))
+		return ctx, cancel, &browserLease{pool: p, browser: best, ctx: best.ctx}, nil
+	}
+}
+
+// pick выбирает браузер с наименьшей загрузкой. Вызывается под p.mu.
+func (p *BrowserPool) pick() *pooledBrowser {
+	var best *pooledBrowser
+	for _, b := range p.browsers {
+		if len(b.bots) >= p.opts.maxBots() {
+			continue
+		}
+		// При равной загрузке предпочитаем уже запущенный процесс
+		if best == nil || len(b.bots) < len(best.bots) ||
+			(len(b.bots) == len(best.bots) && b.alive() && !best.alive()) {
+			best = b
+		}
+	}
+	return best
+}
+
+// launch запускает процесс Chrome для слота пула с номером index
+func (p *BrowserPool) launch(index int) (context.Context, context.CancelFunc, context.CancelFunc, error) {
+	chrome := p.chrome
+	if chrome.UserDataDir != "" {
+		chrome.UserDataDir = filepath.Join(chrome.UserDataDir, fmt.Sprintf("browser-%d", index))
+	}
+
+	var allocCtx context.Context
+	var allocCancel context.CancelFunc
+	if chrome.RemoteURL != "" {
+		allocCtx, allocCancel = chromedp.NewRemoteAllocator(context.Background(), chrome.RemoteURL)
+	} else {
+		allocCtx, allocCancel = chromedp.NewExecAllocator(context.Background(), chrome.execOptions(p.opts.headless())...)
+	}
+	ctx, cancel := chromedp.NewContext(allocCtx)
+
+	// Пустой Run запускает браузер
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		allocCancel()
+		return nil, nil, nil, fmt.Errorf("failed to launch pooled browser #%d: %v", index, err)
+	}
+	log.Printf("Запущен общий браузер #%d", index)
+	return ctx, cancel, allocCancel, nil
+}
+
+// release освобождает место бота в браузере
+func (l *browserLease) release(bot *Bot) {
+	l.pool.mu.Lock()
+	defer l.pool.mu.Unlock()
+	delete(l.browser.bots, bot)
+}
+
+// lost сообщает, что процесс браузера упал или потерял соединение. Слот
+// мог быть уже перезапущен, поэтому проверяется контекст исходного процесса.
+func (l *browserLease) lost() bool {
+	return l.ctx.Err() != nil
+}
+
+// newBrowserContext открывает контекст вкладки бота: в общем пуле,
+// если он настроен, или в собственном браузере
+func (bot *Bot) newBrowserContext() error {
+	bot.mu.RLock()
+	pool := bot.pool
+	bot.mu.RUnlock()
+
+	if pool == nil {
+		allocCtx, allocCancel := bot.newAllocator()
+		ctx, cancel := chromedp.NewContext(allocCtx)
+		bot.mu.Lock()
+		bot.Ctx, bot.CtxCancel, bot.AllocCancel, bot.lease = ctx, cancel, allocCancel, nil
+		bot.mu.Unlock()
+		return nil
+	}
+
+	ctx, cancel, lease, err := pool.acquire(bot)
+	if err != nil {
+		return err
+	}
+	bot.mu.Lock()
+	bot.Ctx, bot.CtxCancel, bot.lease = ctx, cancel, lease
+	bot.AllocCancel = func() { lease.release(bot) }
+	bot.mu.Unlock()
+	return nil
+}
+
+// migrateIfBrowserLost перезапускает бота в другом процессе пула, если его
+// браузер упал, а бота никто не останавливал
+func (bot *Bot) migrateIfBrowserLost() bool {
+	bot.mu.RLock()
+	lease := bot.lease
+	bot.mu.RUnlock()
+
+	if lease == nil || !lease.lost() {
+		return false
+	}
+	if s := bot.GetStatus(); s == StatusStopping || s == StatusStopped {
+		return false
+	}
+
+	log.Printf("Бот %s: общий браузер #%d упал, переносим бота", bot.ID, lease.browser.index)
+	bot.Events.Add("error", fmt.Sprintf("pooled browser #%d crashed, moving bot", lease.browser.index))
+	bot.cancelContexts()
+	go func() {
+		if err := bot.Start(); err != nil {
+			log.Printf("Ошибка переноса бота %s (%s): %v", bot.BotName, bot.ID, err)
+		}
+	}()
+	return true
+}
@@ -0,0 +1,90 @@
+package ssjitsi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigBrowserPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		pooled  bool
+		wantErr string
+	}{
+		{
+			name: "pool with global chrome options",
+			config: `
+chrome:
+  NoSandbox: true
+browser_pool:
+  Browsers: 2
+bots:
+  - BotName: a
+  - BotName: b
+`,
+			pooled: true,
+		},
+		{
+			name: "per-bot chrome options in pool mode",
+			config: `
+browser_pool:
+  Browsers: 1
+bots:
+  - BotName: a
+  - BotName: b
+    Chrome:
+      ProxyServer: socks5://127.0.0.1:1080
+`,
+			wantErr: `bot "b": Chrome options are not supported with browser_pool`,
+		},
+		{
+			name: "per-bot flags in pool mode",
+			config: `
+browser_pool:
+  Browsers: 1
+bots:
+  - BotName: a
+    Chrome:
+      Flags:
+        mute-audio: true
+`,
+			wantErr: `bot "a": Chrome options`,
+		},
+		{
+			name: "per-bot chrome options without pool",
+			config: `
+bots:
+  - BotName: a
+    Chrome:
+      ProxyServer: socks5://127.0.0.1:1080
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config, err := LoadConfig(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range config.Bots {
+				bot := &config.Bots[i]
+				if (bot.pool != nil) != tt.pooled {
+					t.Errorf("bot %s pooled = %v, want %v", bot.BotName, bot.pool != nil, tt.pooled)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package ssjitsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Максимальная длина сообщения чата
+const maxChatMessage = 4096
+
+// Сколько ждать отправки прощального сообщения перед закрытием вкладки
+const leaveMessageTimeout = 3 * time.Second
+
+var (
+	ErrNotJoined             = errors.New("bot has not joined the conference")
+	ErrChatRecipientNotFound = errors.New("chat recipient not found")
+)
+
+// ChatResult - результат отправки сообщения
+type ChatResult struct {
+	Delivered bool   `json:"delivered"`
+	Private   bool   `json:"private"`
+	To        string `json:"to,omitempty"`     // id получателя личного сообщения
+	ToName    string `json:"toName,omitempty"` // Его отображаемое имя
+	Reason    string `json:"reason,omitempty"` // Почему сообщение не доставлено
+}
+
+// chatScript отправляет сообщение через конференцию lib-jitsi-meet. Получатель
+// личного сообщения ищется по id, затем по отображаемому имени.
+const chatScript = `(function (text, to) {
+	const room = window.APP && APP.conference && APP.conference._room;
+	if (!room || !room.isJoined || !room.isJoined()) {
+		return { delivered: false, reason: 'not_joined' };
+	}
+	if (!to) {
+		room.sendTextMessage(text);
+		return { delivered: true, private: false };
+	}
+	const participants = room.getParticipants();
+	const p = participants.find((p) => p.getId() === to) ||
+		participants.find((p) => (p.getDisplayName() || '') === to);
+	if (!p) {
+		return { delivered: false, private: true, reason: 'not_found' };
+	}
+	room.sendPrivateTextMessage(p.getId(), text);
+	return { delivered: true, private: true, to: p.getId(), toName: p.getDisplayName() || '' };
+})(%s, %s)`
+
+// SendChat отправляет сообщение в чат конференции; при заданном to -
+// личное сообщение участнику с таким id или отображаемым именем
+func (bot *Bot) SendChat(ctx context.Context, text, to string) (ChatResult, error) {
+	var res ChatResult
+	if text == "" {
+		return res, errors.New("message is empty")
+	}
+	if len(text) > maxChatMessage {
+		return res, fmt.Errorf("message is longer than %d bytes", maxChatMessage)
+	}
+
+	bot.mu.RLock()
+	tab := bot.Ctx
+	bot.mu.RUnlock()
+	if tab == nil {
+		return res, ErrNotJoined
+	}
+	// Запрос ограничивается и контекстом вызывающего, и временем жизни вкладки
+	tab, cancel := context.WithCancel(tab)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	textJSON, _ := json.Marshal(text)
+	toJSON, _ := json.Marshal(to)
+	err := chromedp.Run(tab, chromedp.Evaluate(fmt.Sprintf(chatScript, textJSON, toJSON), &res))
+	if err != nil {
+		return res, fmt.Errorf("failed to send chat message: %v", err)
+	}
+	switch res.Reason {
+	case "not_joined":
+		return res, ErrNotJoined
+	case "not_found":
+		return res, ErrChatRecipientNotFound
+	}
+
+	if res.Private {
+		bot.Events.Add("info", fmt.Sprintf("private chat message sent to %s", res.ToName))
+	} else {
+		bot.Events.Add("info", "chat message sent")
+	}
+	return res, nil
+}
+
+// sendConfiguredMessage отправляет сообщение из конфигурации (JoinMessage
+// или LeaveMessage); в тексте доступны поля шаблонов сценария входа
+func (bot *Bot) sendConfiguredMessage(ctx context.Context, kind, tpl string) {
+	if tpl == "" {
+		return
+	}
+	text, err := renderTemplate(tpl, bot.joinData(""))
+	if err == nil {
+		_, err = bot.SendChat(ctx, text, "")
+	}
+	if err != nil {
+		log.Printf("Бот %s: не удалось отправить %s: %v", bot.ID, kind, err)
+		bot.Events.Add("error", fmt.Sprintf("%s failed: %v", kind, err))
+	}
+}
+
+// sendLeaveMessage прощается в чате перед остановкой работающего бота
+func (bot *Bot) sendLeaveMessage() {
+	if bot.LeaveMessage == "" || bot.GetStatus() != StatusRunning {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leaveMessageTimeout)
+	defer cancel()
+	bot.sendConfiguredMessage(ctx, "leave message", bot.LeaveMessage)
+	// Даем XMPP соединению отправить сообщение до закрытия вкладки
+	time.Sleep(500 * time.Millisecond)
+}
@@ -0,0 +1,34 @@
+package ssjitsi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSendChatChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{"empty message", "", "message is empty"},
+		{"too long", strings.Repeat("a", maxChatMessage+1), "message is longer than 4096 bytes"},
+		// Длина считается в байтах, а не в символах
+		{"too long in bytes", strings.Repeat("я", maxChatMessage/2+1), "message is longer than 4096 bytes"},
+		{"not joined", strings.Repeat("a", maxChatMessage), ErrNotJoined.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Bot{}).SendChat(context.Background(), tt.text, "")
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("SendChat() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := (&Bot{}).SendChat(context.Background(), "hello", "a1"); !errors.Is(err, ErrNotJoined) {
+		t.Errorf("SendChat() without a tab = %v, want ErrNotJoined", err)
+	}
+}
@@ -0,0 +1,128 @@
+package ssjitsi
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ChromeOptions задает параметры запуска браузера бота. Глобальные параметры
+// из секции chrome действуют для всех ботов, параметры бота их перекрывают.
+type ChromeOptions struct {
+	ExecPath           string                 `yaml:"ExecPath"`           // Путь к исполняемому файлу Chrome
+	Flags              map[string]interface{} `yaml:"Flags"`              // Дополнительные флаги командной строки; false убирает флаг
+	UserDataDir        string                 `yaml:"UserDataDir"`        // Каталог профиля; в глобальной секции - родительский каталог профилей ботов
+	ProxyServer        string                 `yaml:"ProxyServer"`        // Прокси-сервер, например socks5://127.0.0.1:1080
+	WindowWidth        int                    `yaml:"WindowWidth"`        // Ширина окна
+	WindowHeight       int                    `yaml:"WindowHeight"`       // Высота окна
+	UserAgent          string                 `yaml:"UserAgent"`          // Переопределение User-Agent
+	NoSandbox          *bool                  `yaml:"NoSandbox"`          // Запуск без песочницы (нужно при работе от root в контейнере)
+	DisableDevShmUsage *bool                  `yaml:"DisableDevShmUsage"` // Не использовать /dev/shm (по умолчанию включено)
+	RemoteURL          string                 `yaml:"RemoteURL"`          // Подключение к уже запущенному браузеру: ws://... или http://host:9222
+}
+
+// empty сообщает, что в секции не задан ни один параметр
+func (o ChromeOptions) empty() bool {
+	return o.ExecPath == "" && len(o.Flags) == 0 && o.UserDataDir == "" && o.ProxyServer == "" &&
+		o.WindowWidth == 0 && o.WindowHeight == 0 && o.UserAgent == "" && o.NoSandbox == nil &&
+		o.DisableDevShmUsage == nil && o.RemoteURL == ""
+}
+
+// merge накладывает параметры бота на глобальные параметры
+func (o ChromeOptions) merge(bot ChromeOptions, botName string) ChromeOptions {
+	res := o
+	// Глобальный UserDataDir - общий каталог, профиль у каждого бота свой
+	if res.UserDataDir != "" {
+		res.UserDataDir = filepath.Join(res.UserDataDir, SafeFilename(botName))
+	}
+
+	if bot.ExecPath != "" {
+		res.ExecPath = bot.ExecPath
+	}
+	if bot.UserDataDir != "" {
+		res.UserDataDir = bot.UserDataDir
+	}
+	if bot.ProxyServer != "" {
+		res.ProxyServer = bot.ProxyServer
+	}
+	if bot.WindowWidth > 0 && bot.WindowHeight > 0 {
+		res.WindowWidth, res.WindowHeight = bot.WindowWidth, bot.WindowHeight
+	}
+	if bot.UserAgent != "" {
+		res.UserAgent = bot.UserAgent
+	}
+	if bot.NoSandbox != nil {
+		res.NoSandbox = bot.NoSandbox
+	}
+	if bot.DisableDevShmUsage != nil {
+		res.DisableDevShmUsage = bot.DisableDevShmUsage
+	}
+	if bot.RemoteURL != "" {
+		res.RemoteURL = bot.RemoteURL
+	}
+
+	if len(o.Flags) > 0 || len(bot.Flags) > 0 {
+		res.Flags = map[string]interface{}{}
+		for k, v := range o.Flags {
+			res.Flags[k] = v
+		}
+		for k, v := range bot.Flags {
+			res.Flags[k] = v
+		}
+	}
+	return res
+}
+
+// execOptions собирает параметры запуска локального браузера
+func (o ChromeOptions) execOptions(headless bool) []chromedp.ExecAllocatorOption {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("use-fake-ui-for-media-stream", true),
+		chromedp.Flag("headless", headless),
+	)
+
+	if o.ExecPath != "" {
+		opts = append(opts, chromedp.ExecPath(o.ExecPath))
+	}
+	if o.UserDataDir != "" {
+		opts = append(opts, chromedp.UserDataDir(o.UserDataDir))
+	}
+	if o.ProxyServer != "" {
+		opts = append(opts, chromedp.ProxyServer(o.ProxyServer))
+	}
+	if o.WindowWidth > 0 && o.WindowHeight > 0 {
+		opts = append(opts, chromedp.WindowSize(o.WindowWidth, o.WindowHeight))
+	}
+	if o.UserAgent != "" {
+		opts = append(opts, chromedp.UserAgent(o.UserAgent))
+	}
+	if o.NoSandbox != nil {
+		opts = append(opts, chromedp.Flag("no-sandbox", *o.NoSandbox))
+	}
+	if o.DisableDevShmUsage != nil {
+		opts = append(opts, chromedp.Flag("disable-dev-shm-usage", *o.DisableDevShmUsage))
+	}
+
+	// Произвольные флаги задаются последними и перекрывают остальные
+	for name, value := range o.Flags {
+		switch v := value.(type) {
+		case bool, string:
+			opts = append(opts, chromedp.Flag(name, v))
+		case nil:
+			opts = append(opts, chromedp.Flag(name, true))
+		default:
+			opts = append(opts, chromedp.Flag(name, fmt.Sprint(v)))
+		}
+	}
+	return opts
+}
+
+// newAllocator создает allocator контекст браузера: запускает локальный
+// Chrome или подключается к удаленному по RemoteURL
+func (bot *Bot) newAllocator() (context.Context, context.CancelFunc) {
+	if bot.Chrome.RemoteURL != "" {
+		return chromedp.NewRemoteAllocator(context.Background(), bot.Chrome.RemoteURL)
+	}
+	return chromedp.NewExecAllocator(context.Background(), bot.Chrome.execOptions(bot.Headless)...)
+}
@@ -0,0 +1,97 @@
+package ssjitsi
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestChromeOptionsMerge(t *testing.T) {
+	yes, no := true, false
+	global := ChromeOptions{
+		ExecPath:     "/usr/bin/chromium",
+		Flags:        map[string]interface{}{"mute-audio": true, "lang": "en"},
+		UserDataDir:  "/var/lib/ssjitsi/profiles",
+		ProxyServer:  "socks5://127.0.0.1:1080",
+		WindowWidth:  1280,
+		WindowHeight: 720,
+		NoSandbox:    &yes,
+	}
+
+	tests := []struct {
+		name   string
+		global ChromeOptions
+		bot    ChromeOptions
+		want   ChromeOptions
+	}{
+		{
+			name: "empty",
+			want: ChromeOptions{},
+		},
+		{
+			name:   "global only gets a per-bot profile",
+			global: global,
+			want: ChromeOptions{
+				ExecPath:     "/usr/bin/chromium",
+				Flags:        map[string]interface{}{"mute-audio": true, "lang": "en"},
+				UserDataDir:  filepath.Join("/var/lib/ssjitsi/profiles", "Bot_1"),
+				ProxyServer:  "socks5://127.0.0.1:1080",
+				WindowWidth:  1280,
+				WindowHeight: 720,
+				NoSandbox:    &yes,
+			},
+		},
+		{
+			name:   "bot overrides global",
+			global: global,
+			bot: ChromeOptions{
+				ExecPath:           "/opt/chrome/chrome",
+				Flags:              map[string]interface{}{"lang": "ru", "mute-audio": false},
+				UserDataDir:        "/tmp/bot-profile",
+				ProxyServer:        "http://proxy:3128",
+				WindowWidth:        1920,
+				WindowHeight:       1080,
+				UserAgent:          "Recorder",
+				NoSandbox:          &no,
+				DisableDevShmUsage: &no,
+				RemoteURL:          "ws://chrome:9222",
+			},
+			want: ChromeOptions{
+				ExecPath:           "/opt/chrome/chrome",
+				Flags:              map[string]interface{}{"lang": "ru", "mute-audio": false},
+				UserDataDir:        "/tmp/bot-profile",
+				ProxyServer:        "http://proxy:3128",
+				WindowWidth:        1920,
+				WindowHeight:       1080,
+				UserAgent:          "Recorder",
+				NoSandbox:          &no,
+				DisableDevShmUsage: &no,
+				RemoteURL:          "ws://chrome:9222",
+			},
+		},
+		{
+			name:   "window size needs both dimensions",
+			global: ChromeOptions{WindowWidth: 1280, WindowHeight: 720},
+			bot:    ChromeOptions{WindowWidth: 1920},
+			want:   ChromeOptions{WindowWidth: 1280, WindowHeight: 720},
+		},
+		{
+			name:   "flags are merged",
+			global: ChromeOptions{Flags: map[string]interface{}{"a": true}},
+			bot:    ChromeOptions{Flags: map[string]interface{}{"b": "1"}},
+			want:   ChromeOptions{Flags: map[string]interface{}{"a": true, "b": "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			globalFlags := fmt.Sprint(tt.global.Flags)
+			got := tt.global.merge(tt.bot, "Bot/1")
+			if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", tt.want) {
+				t.Errorf("merge() = %+v, want %+v", got, tt.want)
+			}
+			if fmt.Sprint(tt.global.Flags) != globalFlags {
+				t.Errorf("merge() changed global flags: %v", tt.global.Flags)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package ssjitsi
 
 import (
+	"fmt"
 	"os"
 
 	"gopkg.in/yaml.v2"
@@ -12,6 +13,14 @@ type Config struct {
 	WebUsername string `yaml:"web_username"` // Логин для доступа к веб-консоли
 	WebPassword string `yaml:"web_password"` // Пароль для доступа к веб-консоли
 	Bots        []Bot  `yaml:"bots"`
+
+	JoinProfiles map[string][]JoinStep `yaml:"join_profiles"` // Сценарии входа, перекрывают встроенные jwt и form
+	Chrome       ChromeOptions         `yaml:"chrome"`        // Параметры запуска браузера для всех ботов
+	BrowserPool  BrowserPoolOptions    `yaml:"browser_pool"`  // Общие процессы Chrome с вкладкой на каждого бота
+	Startup      StartupOptions        `yaml:"startup"`       // Ограничения одновременного запуска ботов
+	Script       string                `yaml:"script"`        // Путь к скрипту записи вместо встроенного для всех ботов
+	Upload       UploadOptions         `yaml:"upload"`        // Передача чанков записи по HTTP через loopback
+	AuditLog     string                `yaml:"audit_log"`     // Журнал аудита действий через API (по умолчанию {DataDir}/audit.jsonl)
 }
 
 // LoadConfig загружает конфигурацию из файла
@@ -27,5 +36,57 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, err
 	}
 
+	for name, steps := range config.JoinProfiles {
+		if err := validateJoinProfile(name, steps); err != nil {
+			return nil, err
+		}
+	}
+	var pool *BrowserPool
+	if config.BrowserPool.enabled() {
+		pool = NewBrowserPool(config.BrowserPool, config.Chrome)
+	}
+	var launcher *Launcher
+	if config.Startup.enabled() {
+		launcher = NewLauncher(config.Startup)
+	}
+	var uploader *ChunkServer
+	if !config.Upload.Disabled {
+		uploader = NewChunkServer(config.Upload)
+	}
+	for i := range config.Bots {
+		bot := &config.Bots[i]
+		bot.pool = pool
+		bot.uploader = uploader
+		bot.launcher = launcher
+		bot.auditLog = config.AuditLog
+		if bot.Script == "" {
+			bot.Script = config.Script
+		}
+		bot.joinProfiles = config.JoinProfiles
+		// Процессы пула запускаются с глобальными параметрами
+		if pool != nil && !bot.Chrome.empty() {
+			return nil, fmt.Errorf("bot %q: Chrome options are not supported with browser_pool, use the global chrome section", bot.BotName)
+		}
+		bot.Chrome = config.Chrome.merge(bot.Chrome, bot.BotName)
+		if err := bot.Recording.validate(); err != nil {
+			return nil, fmt.Errorf("bot %q: %v", bot.BotName, err)
+		}
+		if err := bot.Screencast.validate(); err != nil {
+			return nil, fmt.Errorf("bot %q: %v", bot.BotName, err)
+		}
+		if err := bot.Timelapse.validate(); err != nil {
+			return nil, fmt.Errorf("bot %q: %v", bot.BotName, err)
+		}
+		if err := bot.Announcement.validate(); err != nil {
+			return nil, fmt.Errorf("bot %q: %v", bot.BotName, err)
+		}
+		if err := bot.Stats.validate(); err != nil {
+			return nil, fmt.Errorf("bot %q: %v", bot.BotName, err)
+		}
+		if _, err := bot.joinSteps(); err != nil {
+			return nil, fmt.Errorf("bot %q: %v", bot.BotName, err)
+		}
+	}
+
 	return &config, nil
 }
@@ -30,6 +30,27 @@ func NewEmbeddedServer(server *HttpServer, webUsername, webPassword string) *gin
 		api.GET("/:id/screenshot", server.Screenshot)
 		api.POST("/:id/stop", server.StopBot)
 		api.POST("/:id/restart", server.RestartBot)
+		api.POST("/:id/start", server.StartBot)
+		api.GET("/:id/events", server.BotEvents)
+		api.GET("/:id/participants", server.Participants)
+		api.GET("/:id/stats", server.Stats)
+		api.POST("/:id/announce", server.Announce)
+		api.POST("/:id/chat", server.Chat)
+		// Действия модератора доступны только при настроенном BasicAuth
+		mod := api.Group("", RequireAuthMiddleware(webUsername, webPassword))
+		mod.GET("/:id/lobby", server.LobbyState)
+		mod.POST("/:id/lobby", server.ToggleLobby)
+		mod.POST("/:id/lobby/admit", server.AdmitLobby)
+		mod.POST("/:id/lobby/reject", server.RejectLobby)
+		mod.POST("/:id/mute-all", server.MuteAll)
+		mod.POST("/:id/kick", server.Kick)
+		mod.POST("/:id/subject", server.SetSubject)
+		api.GET("/logs", server.Logs)
+		api.GET("/sessions", server.ListSessions)
+		api.GET("/sessions/:sid", server.SessionFiles)
+		api.GET("/sessions/:sid/files/*name", server.DownloadSessionFile)
+		api.GET("/sessions/:sid/screenshots", server.SessionScreenshots)
+		api.GET("/sessions/:sid/talktime", server.SessionTalkTime)
 	}
 
 	// Обработка всех запросов
@@ -0,0 +1,57 @@
+package ssjitsi
+
+import (
+	"sync"
+	"time"
+)
+
+// Размер кольцевого буфера событий бота по умолчанию
+const defaultEventLogSize = 500
+
+// Event описывает событие в жизненном цикле бота
+type Event struct {
+	Seq     int64     `json:"seq"`
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"` // status, error, info
+	Message string    `json:"message"`
+}
+
+// EventLog хранит последние события в кольцевом буфере.
+// Нулевое значение готово к использованию.
+type EventLog struct {
+	mu     sync.RWMutex
+	seq    int64
+	size   int
+	events []Event
+}
+
+// Add добавляет событие в журнал
+func (l *EventLog) Add(typ, message string) Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size == 0 {
+		l.size = defaultEventLogSize
+	}
+	l.seq++
+	ev := Event{Seq: l.seq, Time: time.Now(), Type: typ, Message: message}
+	l.events = append(l.events, ev)
+	if len(l.events) > l.size {
+		l.events = l.events[len(l.events)-l.size:]
+	}
+	return ev
+}
+
+// Since возвращает события с номером больше since
+func (l *EventLog) Since(since int64) []Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	res := make([]Event, 0)
+	for _, ev := range l.events {
+		if ev.Seq > since {
+			res = append(res, ev)
+		}
+	}
+	return res
+}
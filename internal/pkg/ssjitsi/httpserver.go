@@ -3,8 +3,12 @@ package ssjitsi
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,22 +34,56 @@ type BotInfo struct {
 	BotName    string    `json:"botName"`
 	Server     string    `json:"server"`
 	AuthMethod string    `json:"authMethod"`
-	Status     string    `json:"status"` // Статус бота: running, stopped, starting, stopping
+	Status     string    `json:"status"`                  // Статус бота: running, stopped, starting, stopping, queued, in_lobby, wrong_password
+	Error      string    `json:"error,omitempty"`         // Последняя ошибка запуска
+	QueuePos   int       `json:"queuePosition,omitempty"` // Позиция в очереди запуска для статуса queued
+	Caps       []string  `json:"capabilities,omitempty"`  // Возможности внедренного скрипта и плагинов
 	LastUpdate time.Time `json:"lastUpdate"`
 }
 
 type HttpServer struct {
 	bots   map[string]*Bot
 	router *gin.Engine
+	logs   *LogBuffer
 }
 
 func (h *HttpServer) AddBot(b *Bot) {
 	h.bots[b.ID] = b
 }
 
+// SetLogBuffer подключает буфер журнала сервера для отдачи через API
+func (h *HttpServer) SetLogBuffer(l *LogBuffer) {
+	h.logs = l
+}
+
+// dataDirs возвращает уникальные каталоги данных всех ботов
+func (h *HttpServer) dataDirs() []string {
+	seen := map[string]bool{}
+	dirs := make([]string, 0)
+	for _, bot := range h.bots {
+		if bot.DataDir != "" && !seen[bot.DataDir] {
+			seen[bot.DataDir] = true
+			dirs = append(dirs, bot.DataDir)
+		}
+	}
+	return dirs
+}
+
+// sinceParam читает параметр запроса since (номер последнего полученного события)
+func sinceParam(c *gin.Context) (int64, error) {
+	v := c.Query("since")
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
 // getAuthMethod определяет метод авторизации бота
 func getAuthMethod(b *Bot) string {
-	if b.JWTAppID != "" && b.JWTAppSecret != "" {
+	if b.TokenService.URL != "" {
+		return "TokenService"
+	}
+	if b.JWTAppID != "" && (b.JWTAppSecret != "" || b.JWT.hasKey()) {
 		return "JWT"
 	}
 	if b.Username != "" || b.Pass != "" {
@@ -81,6 +119,9 @@ func (h *HttpServer) ListBots(c *gin.Context) {
 			Server:     bot.JitsiServer,
 			AuthMethod: getAuthMethod(bot),
 			Status:     bot.GetStatus(),
+			Error:      bot.GetError(),
+			QueuePos:   bot.QueuePosition(),
+			Caps:       bot.Capabilities(),
 			LastUpdate: time.Now(),
 		})
 	}
@@ -115,7 +156,7 @@ func (h *HttpServer) HTML(c *gin.Context) {
 		chromedp.OuterHTML("body", &res, chromedp.ByQuery),
 	)
 	if err != nil {
-		fmt.Println(err)
+		log.Printf("Бот %s: не удалось получить HTML страницы: %v", bot.ID, err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   true,
 			"message": err.Error(),
@@ -123,7 +164,6 @@ func (h *HttpServer) HTML(c *gin.Context) {
 		return
 	}
 
-	fmt.Println(strings.TrimSpace(res))
 	c.JSON(http.StatusOK, res)
 }
 
@@ -151,7 +191,7 @@ func (h *HttpServer) Screenshot(c *gin.Context) {
 
 	// Проверяем статус бота
 	status := bot.GetStatus()
-	if status != "running" {
+	if status != StatusRunning {
 		log.Printf("Попытка сделать скриншот бота %s в статусе %s", id, status)
 		newError(c, http.StatusServiceUnavailable, fmt.Errorf("bot is not running (status: %s)", status))
 		return
@@ -181,7 +221,7 @@ func (h *HttpServer) Screenshot(c *gin.Context) {
 // @Failure      400  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
-// @Router       /:id/stop [post]
+// @Router       /{id}/stop [post]
 func (h *HttpServer) StopBot(c *gin.Context) {
 	id := c.Param("id")
 	log.Printf("Получен запрос на остановку бота с ID: %s", id)
@@ -224,7 +264,7 @@ func (h *HttpServer) StopBot(c *gin.Context) {
 // @Failure      400  {object}  error
 // @Failure      404  {object}  error
 // @Failure      500  {object}  error
-// @Router       /:id/restart [post]
+// @Router       /{id}/restart [post]
 func (h *HttpServer) RestartBot(c *gin.Context) {
 	id := c.Param("id")
 	log.Printf("Получен запрос на перезапуск бота с ID: %s", id)
@@ -257,6 +297,608 @@ func (h *HttpServer) RestartBot(c *gin.Context) {
 	})
 }
 
+// StartBot godoc
+// @Summary      Start bot
+// @Description  start a stopped bot by ID
+// @Tags         bot
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Bot ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      409  {object}  error
+// @Router       /{id}/start [post]
+func (h *HttpServer) StartBot(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("Получен запрос на запуск бота с ID: %s", id)
+
+	if id == "" {
+		newError(c, http.StatusBadRequest, errors.New("id required"))
+		return
+	}
+	bot, ok := h.bots[id]
+	if !ok {
+		log.Printf("Бот с ID %s не найден", id)
+		newError(c, http.StatusNotFound, errors.New("bot not found"))
+		return
+	}
+
+	status := bot.GetStatus()
+	if !bot.isIdle() {
+		newError(c, http.StatusConflict, fmt.Errorf("bot is not stopped (status: %s)", status))
+		return
+	}
+
+	go func() {
+		err := bot.Start()
+		if err != nil {
+			log.Printf("Ошибка при запуске бота %s (%s): %v", bot.BotName, bot.ID, err)
+		}
+	}()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bot start initiated",
+		"status":  bot.GetStatus(),
+	})
+}
+
+// BotEvents godoc
+// @Summary      Bot events
+// @Description  get bot lifecycle events newer than since
+// @Tags         bot
+// @Produce      json
+// @Param        id     path      string  true   "Bot ID"
+// @Param        since  query     int     false  "Last received event number"
+// @Success      200  {array}   Event
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Router       /{id}/events [get]
+func (h *HttpServer) BotEvents(c *gin.Context) {
+	bot, ok := h.bots[c.Param("id")]
+	if !ok {
+		newError(c, http.StatusNotFound, errors.New("bot not found"))
+		return
+	}
+	since, err := sinceParam(c)
+	if err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, bot.Events.Since(since))
+}
+
+// Logs godoc
+// @Summary      Server log
+// @Description  get server log lines newer than since
+// @Tags         main
+// @Produce      json
+// @Param        since  query     int     false  "Last received line number"
+// @Success      200  {array}   Event
+// @Failure      400  {object}  error
+// @Router       /logs [get]
+func (h *HttpServer) Logs(c *gin.Context) {
+	since, err := sinceParam(c)
+	if err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+	if h.logs == nil {
+		c.JSON(http.StatusOK, []Event{})
+		return
+	}
+	c.JSON(http.StatusOK, h.logs.Since(since))
+}
+
+// Participants godoc
+// @Summary      Meeting participants
+// @Description  list participants of the bot's meeting: role, mute state, connection status, join time and whether they are recorded
+// @Tags         bot
+// @Produce      json
+// @Param        id   path      string  true  "Bot ID"
+// @Success      200  {array}   Participant
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/participants [get]
+func (h *HttpServer) Participants(c *gin.Context) {
+	bot, ok := h.bots[c.Param("id")]
+	if !ok {
+		newError(c, http.StatusNotFound, errors.New("bot not found"))
+		return
+	}
+	if status := bot.GetStatus(); status != StatusRunning {
+		newError(c, http.StatusServiceUnavailable, fmt.Errorf("bot is not running (status: %s)", status))
+		return
+	}
+
+	list, err := bot.Participants(c.Request.Context())
+	if errors.Is(err, ErrNotJoined) {
+		newError(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		newError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Stats godoc
+// @Summary      Connection quality
+// @Description  latest WebRTC statistics of incoming audio per participant: packet loss, jitter, bitrate, concealed samples and round-trip time
+// @Tags         bot
+// @Produce      json
+// @Param        id   path      string  true  "Bot ID"
+// @Success      200  {object}  StatsSample
+// @Failure      404  {object}  error
+// @Failure      409  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/stats [get]
+func (h *HttpServer) Stats(c *gin.Context) {
+	bot, ok := h.bots[c.Param("id")]
+	if !ok {
+		newError(c, http.StatusNotFound, errors.New("bot not found"))
+		return
+	}
+	if status := bot.GetStatus(); status != StatusRunning {
+		newError(c, http.StatusServiceUnavailable, fmt.Errorf("bot is not running (status: %s)", status))
+		return
+	}
+
+	stats, err := bot.CurrentStats()
+	if errors.Is(err, ErrStatsDisabled) {
+		newError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		newError(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// Announce godoc
+// @Summary      Play announcement
+// @Description  play a WAV clip into the meeting: a preconfigured clip by name, an uploaded file (raw body or multipart field "file") or the bot's join announcement
+// @Tags         bot
+// @Accept       audio/wav
+// @Produce      json
+// @Param        id    path      string  true   "Bot ID"
+// @Param        clip  query     string  false  "Name of a clip from Announcement.Clips"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      409  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/announce [post]
+func (h *HttpServer) Announce(c *gin.Context) {
+	bot, ok := h.bots[c.Param("id")]
+	if !ok {
+		newError(c, http.StatusNotFound, errors.New("bot not found"))
+		return
+	}
+	if status := bot.GetStatus(); status != StatusRunning {
+		newError(c, http.StatusServiceUnavailable, fmt.Errorf("bot is not running (status: %s)", status))
+		return
+	}
+
+	data, err := announceData(c, bot)
+	if errors.Is(err, ErrClipNotFound) {
+		newError(c, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	duration, err := bot.Announce(c.Request.Context(), data)
+	switch {
+	case errors.Is(err, ErrAnnounceDisabled), errors.Is(err, ErrAnnounceBusy):
+		newError(c, http.StatusConflict, err)
+		return
+	case err != nil:
+		newError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"duration": duration,
+	})
+}
+
+// announceData возвращает клип из запроса: загруженный файл, именованный
+// клип или клип входа
+func announceData(c *gin.Context, bot *Bot) ([]byte, error) {
+	if clip := c.Query("clip"); clip != "" {
+		return bot.announceClip(clip)
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAnnounceSize)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		return data, checkWAV(data)
+	}
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return bot.announceClip("")
+	}
+	// Без проверки неподходящий файл отклонил бы только decodeAudioData на странице
+	return data, checkWAV(data)
+}
+
+// ChatRequest - сообщение для отправки в чат конференции
+type ChatRequest struct {
+	Message string `json:"message" binding:"required"`
+	To      string `json:"to"` // id или отображаемое имя получателя личного сообщения
+}
+
+// Chat godoc
+// @Summary      Send chat message
+// @Description  post a message to the meeting chat, or privately to a participant by ID or display name
+// @Tags         bot
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string       true  "Bot ID"
+// @Param        message  body      ChatRequest  true  "Message"
+// @Success      200  {object}  ChatResult
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/chat [post]
+func (h *HttpServer) Chat(c *gin.Context) {
+	bot, ok := h.bots[c.Param("id")]
+	if !ok {
+		newError(c, http.StatusNotFound, errors.New("bot not found"))
+		return
+	}
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Message) > maxChatMessage {
+		newError(c, http.StatusBadRequest, fmt.Errorf("message is longer than %d bytes", maxChatMessage))
+		return
+	}
+	if status := bot.GetStatus(); status != StatusRunning {
+		newError(c, http.StatusServiceUnavailable, fmt.Errorf("bot is not running (status: %s)", status))
+		return
+	}
+
+	res, err := bot.SendChat(c.Request.Context(), req.Message, req.To)
+	switch {
+	case errors.Is(err, ErrChatRecipientNotFound):
+		c.JSON(http.StatusNotFound, res)
+	case errors.Is(err, ErrNotJoined):
+		newError(c, http.StatusServiceUnavailable, err)
+	case err != nil:
+		newError(c, http.StatusInternalServerError, err)
+	default:
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// LobbyToggleRequest включает или выключает лобби
+type LobbyToggleRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// LobbyDecisionRequest - кого допустить или отклонить
+type LobbyDecisionRequest struct {
+	ID  string `json:"id"`  // id или отображаемое имя ожидающего
+	All bool   `json:"all"` // Все ожидающие
+}
+
+// KickRequest - кого удалить из конференции
+type KickRequest struct {
+	ID     string `json:"id" binding:"required"` // id или отображаемое имя участника
+	Reason string `json:"reason"`
+}
+
+// SubjectRequest - новая тема встречи
+type SubjectRequest struct {
+	Subject string `json:"subject"`
+}
+
+// moderate выполняет действие модератора и переводит ошибки в коды ответа
+func (h *HttpServer) moderate(c *gin.Context, action string, args map[string]interface{}) {
+	bot, ok := h.bots[c.Param("id")]
+	if !ok {
+		newError(c, http.StatusNotFound, errors.New("bot not found"))
+		return
+	}
+	if status := bot.GetStatus(); status != StatusRunning {
+		newError(c, http.StatusServiceUnavailable, fmt.Errorf("bot is not running (status: %s)", status))
+		return
+	}
+
+	actor, _, _ := c.Request.BasicAuth()
+	res, err := bot.Moderate(c.Request.Context(), action, args, actor, c.ClientIP())
+	switch {
+	case errors.Is(err, ErrNotModerator):
+		newError(c, http.StatusForbidden, err)
+	case errors.Is(err, ErrParticipantNotFound):
+		newError(c, http.StatusNotFound, err)
+	case errors.Is(err, ErrNotJoined):
+		newError(c, http.StatusServiceUnavailable, err)
+	case err != nil:
+		newError(c, http.StatusInternalServerError, err)
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"result":  res,
+		})
+	}
+}
+
+// LobbyState godoc
+// @Summary      Lobby state
+// @Description  get whether the lobby is enabled and who is knocking; requires moderator rights
+// @Tags         moderator
+// @Produce      json
+// @Param        id   path      string  true  "Bot ID"
+// @Success      200  {object}  LobbyState
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/lobby [get]
+func (h *HttpServer) LobbyState(c *gin.Context) {
+	h.moderate(c, ModLobbyState, nil)
+}
+
+// ToggleLobby godoc
+// @Summary      Toggle lobby
+// @Description  enable or disable the meeting lobby; requires moderator rights
+// @Tags         moderator
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "Bot ID"
+// @Param        request  body      LobbyToggleRequest  true  "Lobby state"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  error
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/lobby [post]
+func (h *HttpServer) ToggleLobby(c *gin.Context) {
+	var req LobbyToggleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+	h.moderate(c, ModLobbyToggle, map[string]interface{}{"enabled": req.Enabled})
+}
+
+// AdmitLobby godoc
+// @Summary      Admit from lobby
+// @Description  admit a knocking participant (or all of them); requires moderator rights
+// @Tags         moderator
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                true  "Bot ID"
+// @Param        request  body      LobbyDecisionRequest  true  "Participant"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  error
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/lobby/admit [post]
+func (h *HttpServer) AdmitLobby(c *gin.Context) {
+	h.lobbyDecision(c, ModAdmit)
+}
+
+// RejectLobby godoc
+// @Summary      Reject from lobby
+// @Description  reject a knocking participant (or all of them); requires moderator rights
+// @Tags         moderator
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                true  "Bot ID"
+// @Param        request  body      LobbyDecisionRequest  true  "Participant"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  error
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/lobby/reject [post]
+func (h *HttpServer) RejectLobby(c *gin.Context) {
+	h.lobbyDecision(c, ModReject)
+}
+
+func (h *HttpServer) lobbyDecision(c *gin.Context, action string) {
+	var req LobbyDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.ID == "" && !req.All {
+		newError(c, http.StatusBadRequest, errors.New("id or all is required"))
+		return
+	}
+	h.moderate(c, action, map[string]interface{}{"id": req.ID, "all": req.All})
+}
+
+// MuteAll godoc
+// @Summary      Mute all
+// @Description  mute the microphones of all participants; requires moderator rights
+// @Tags         moderator
+// @Produce      json
+// @Param        id   path      string  true  "Bot ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/mute-all [post]
+func (h *HttpServer) MuteAll(c *gin.Context) {
+	h.moderate(c, ModMuteAll, nil)
+}
+
+// Kick godoc
+// @Summary      Kick participant
+// @Description  remove a participant from the meeting by ID or display name; requires moderator rights
+// @Tags         moderator
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string       true  "Bot ID"
+// @Param        request  body      KickRequest  true  "Participant"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  error
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/kick [post]
+func (h *HttpServer) Kick(c *gin.Context) {
+	var req KickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+	h.moderate(c, ModKick, map[string]interface{}{"id": req.ID, "reason": req.Reason})
+}
+
+// SetSubject godoc
+// @Summary      Set meeting subject
+// @Description  change the meeting subject; requires moderator rights
+// @Tags         moderator
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string          true  "Bot ID"
+// @Param        request  body      SubjectRequest  true  "Subject"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  error
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      503  {object}  error
+// @Router       /{id}/subject [post]
+func (h *HttpServer) SetSubject(c *gin.Context) {
+	var req SubjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+	h.moderate(c, ModSubject, map[string]interface{}{"subject": req.Subject})
+}
+
+// ListSessions godoc
+// @Summary      List sessions
+// @Description  get recording sessions from bot data directories
+// @Tags         sessions
+// @Produce      json
+// @Success      200  {array}   SessionInfo
+// @Router       /sessions [get]
+func (h *HttpServer) ListSessions(c *gin.Context) {
+	c.JSON(http.StatusOK, listSessions(h.dataDirs()))
+}
+
+// SessionFiles godoc
+// @Summary      Session files
+// @Description  get files of a recording session
+// @Tags         sessions
+// @Produce      json
+// @Param        sid  path      string  true  "Session ID"
+// @Success      200  {array}   SessionFile
+// @Failure      404  {object}  error
+// @Router       /sessions/{sid} [get]
+func (h *HttpServer) SessionFiles(c *gin.Context) {
+	dir, err := findSession(h.dataDirs(), c.Param("sid"))
+	if err != nil {
+		newError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, listSessionFiles(dir))
+}
+
+// DownloadSessionFile godoc
+// @Summary      Download session file
+// @Description  download a file of a recording session
+// @Tags         sessions
+// @Produce      octet-stream
+// @Param        sid   path      string  true  "Session ID"
+// @Param        name  path      string  true  "File name"
+// @Success      200  {file}    file
+// @Failure      400  {object}  error
+// @Failure      404  {object}  error
+// @Router       /sessions/{sid}/files/{name} [get]
+func (h *HttpServer) DownloadSessionFile(c *gin.Context) {
+	dir, err := findSession(h.dataDirs(), c.Param("sid"))
+	if err != nil {
+		newError(c, http.StatusNotFound, err)
+		return
+	}
+	path, err := sessionFilePath(dir, c.Param("name"))
+	if err != nil {
+		newError(c, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		newError(c, http.StatusNotFound, errors.New("file not found"))
+		return
+	}
+	c.FileAttachment(path, filepath.Base(path))
+}
+
+// SessionScreenshots godoc
+// @Summary      Session screenshots
+// @Description  list periodic screenshots of a recording session, oldest first
+// @Tags         sessions
+// @Produce      json
+// @Param        sid  path      string  true  "Session ID"
+// @Success      200  {array}   Screenshot
+// @Failure      404  {object}  error
+// @Router       /sessions/{sid}/screenshots [get]
+func (h *HttpServer) SessionScreenshots(c *gin.Context) {
+	dir, err := findSession(h.dataDirs(), c.Param("sid"))
+	if err != nil {
+		newError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, listScreenshots(dir))
+}
+
+// SessionTalkTime godoc
+// @Summary      Session talk time
+// @Description  speaking time per participant built from audio levels: totals, percentages, turns and the longest monologue
+// @Tags         sessions
+// @Produce      json
+// @Param        sid        path      string  true   "Session ID"
+// @Param        intervals  query     bool    false  "Include speaking intervals of each participant"
+// @Success      200  {object}  TalkTime
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /sessions/{sid}/talktime [get]
+func (h *HttpServer) SessionTalkTime(c *gin.Context) {
+	sid := c.Param("sid")
+	dir, err := findSession(h.dataDirs(), sid)
+	if err != nil {
+		newError(c, http.StatusNotFound, err)
+		return
+	}
+	withIntervals, _ := strconv.ParseBool(c.Query("intervals"))
+	res, err := sessionTalkTime(dir, sid, withIntervals)
+	if err != nil {
+		newError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
 // BasicAuthMiddleware создает middleware для базовой авторизации
 func BasicAuthMiddleware(username, password string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -276,6 +918,19 @@ func BasicAuthMiddleware(username, password string) gin.HandlerFunc {
 	}
 }
 
+// RequireAuthMiddleware закрывает маршруты, если BasicAuth не настроен: без
+// учетных данных действие нельзя приписать пользователю в журнале аудита
+func RequireAuthMiddleware(username, password string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if username == "" || password == "" {
+			newError(c, http.StatusForbidden, errors.New("moderator actions require web_username and web_password to be configured"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 func NewHttpServer(webUsername, webPassword string) *HttpServer {
 	srv := HttpServer{bots: map[string]*Bot{}, router: gin.Default()}
 
@@ -299,6 +954,27 @@ func NewHttpServer(webUsername, webPassword string) *HttpServer {
 		v1.GET("/:id/screenshot", srv.Screenshot)
 		v1.POST("/:id/stop", srv.StopBot)
 		v1.POST("/:id/restart", srv.RestartBot)
+		v1.POST("/:id/start", srv.StartBot)
+		v1.GET("/:id/events", srv.BotEvents)
+		v1.GET("/:id/participants", srv.Participants)
+		v1.GET("/:id/stats", srv.Stats)
+		v1.POST("/:id/announce", srv.Announce)
+		v1.POST("/:id/chat", srv.Chat)
+		// Действия модератора доступны только при настроенном BasicAuth
+		mod := v1.Group("", RequireAuthMiddleware(webUsername, webPassword))
+		mod.GET("/:id/lobby", srv.LobbyState)
+		mod.POST("/:id/lobby", srv.ToggleLobby)
+		mod.POST("/:id/lobby/admit", srv.AdmitLobby)
+		mod.POST("/:id/lobby/reject", srv.RejectLobby)
+		mod.POST("/:id/mute-all", srv.MuteAll)
+		mod.POST("/:id/kick", srv.Kick)
+		mod.POST("/:id/subject", srv.SetSubject)
+		v1.GET("/logs", srv.Logs)
+		v1.GET("/sessions", srv.ListSessions)
+		v1.GET("/sessions/:sid", srv.SessionFiles)
+		v1.GET("/sessions/:sid/files/*name", srv.DownloadSessionFile)
+		v1.GET("/sessions/:sid/screenshots", srv.SessionScreenshots)
+		v1.GET("/sessions/:sid/talktime", srv.SessionTalkTime)
 	}
 	srv.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
@@ -0,0 +1,52 @@
+package ssjitsi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestModeratorRoutesRequireAuth(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	gin.DefaultWriter = io.Discard
+
+	tests := []struct {
+		name       string
+		user, pass string
+		auth       bool
+		method     string
+		path       string
+		status     int
+	}{
+		{"kick without credentials", "", "", false, http.MethodPost, "/api/v1/x/kick", http.StatusForbidden},
+		{"lobby without credentials", "", "", false, http.MethodGet, "/api/v1/x/lobby", http.StatusForbidden},
+		{"mute-all without credentials", "", "", false, http.MethodPost, "/api/v1/x/mute-all", http.StatusForbidden},
+		{"bots without credentials", "", "", false, http.MethodGet, "/api/v1/bots", http.StatusOK},
+		{"kick unauthenticated", "admin", "secret", false, http.MethodPost, "/api/v1/x/kick", http.StatusUnauthorized},
+		{"lobby authenticated", "admin", "secret", true, http.MethodGet, "/api/v1/x/lobby", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewHttpServer(tt.user, tt.pass)
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.auth {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+
+			router := NewEmbeddedServer(srv, tt.user, tt.pass)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("embedded status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package ssjitsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
+)
+
+// Время ожидания входа в конференцию по умолчанию
+const defaultJoinTimeout = 60 * time.Second
+
+// Время ожидания допуска из лобби по умолчанию
+const defaultLobbyTimeout = 5 * time.Minute
+
+// Селекторы элементов входа
+const (
+	roomPasswordSelector = `input[name="lockKey"]`
+	lobbyNameSelector    = `[data-testid="lobby.nameField"]`
+	lobbyKnockSelector   = `[data-testid="lobby.knockButton"]`
+)
+
+var (
+	ErrRoomPasswordRequired = errors.New("room is locked with a password")
+	ErrWrongRoomPassword    = errors.New("wrong room password")
+	ErrJoinTimeout          = errors.New("timed out waiting to join the conference")
+	ErrLobbyNotAdmitted     = errors.New("not admitted from the lobby in time")
+	ErrLobbyRejected        = errors.New("rejected by a moderator in the lobby")
+)
+
+// joinStateScript определяет, на каком этапе входа находится страница
+const joinStateScript = `(function() {
+	const conf = window.APP && APP.conference;
+	if (conf && conf.isJoined && conf.isJoined()) {
+		return 'joined';
+	}
+	const state = window.APP && APP.store ? APP.store.getState() : {};
+	const lobby = state['features/lobby'];
+	if (lobby && lobby.lobbyVisible) {
+		return lobby.knocking ? 'knocking' : 'lobby';
+	}
+	const error = state['features/base/conference'] && state['features/base/conference'].error;
+	if (error && error.name === 'conference.connectionError.accessDenied') {
+		return 'rejected';
+	}
+	if (document.querySelector('` + roomPasswordSelector + `')) {
+		return 'password';
+	}
+	return 'pending';
+})()`
+
+// evalAwait настраивает Evaluate на ожидание Promise
+func evalAwait(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+	return p.WithAwaitPromise(true)
+}
+
+// joinTimeout возвращает таймаут входа с учетом значения по умолчанию
+func (bot *Bot) joinTimeout() time.Duration {
+	if bot.JoinTimeout > 0 {
+		return bot.JoinTimeout
+	}
+	return defaultJoinTimeout
+}
+
+// lobbyTimeout возвращает время ожидания в лобби с учетом значения по умолчанию
+func (bot *Bot) lobbyTimeout() time.Duration {
+	if bot.LobbyTimeout > 0 {
+		return bot.LobbyTimeout
+	}
+	return defaultLobbyTimeout
+}
+
+// waitJoined дожидается входа в конференцию, заполняя пароль комнаты,
+// если он запрошен, и ожидая допуска, если включено лобби
+func (bot *Bot) waitJoined(ctx context.Context) error {
+	deadline := time.Now().Add(bot.joinTimeout())
+	var passwordSentAt, lobbySince, knockedAt time.Time
+
+	for {
+		var state string
+		if err := chromedp.Run(ctx, chromedp.Evaluate(joinStateScript, &state)); err != nil {
+			return err
+		}
+
+		switch state {
+		case "joined":
+			if !lobbySince.IsZero() {
+				log.Printf("Бот %s: допущен из лобби", bot.ID)
+				bot.Events.Add("info", "admitted from lobby")
+			}
+			return nil
+
+		case "rejected":
+			return ErrLobbyRejected
+
+		case "lobby", "knocking":
+			if lobbySince.IsZero() {
+				lobbySince = time.Now()
+				// Пока ждем в лобби, действует собственный таймаут
+				deadline = lobbySince.Add(bot.lobbyTimeout())
+				bot.SetStatus(StatusInLobby)
+				log.Printf("Бот %s: в комнате включено лобби, ожидаем допуска", bot.ID)
+			}
+			// Стучимся повторно, если страница так и не перешла в ожидание
+			if state == "lobby" && time.Since(knockedAt) > 5*time.Second {
+				if err := bot.knock(ctx); err != nil {
+					return err
+				}
+				knockedAt = time.Now()
+			}
+
+		case "password":
+			if bot.RoomPassword == "" {
+				return ErrRoomPasswordRequired
+			}
+			// Диалог снова открылся после отправки - пароль не подошел
+			if !passwordSentAt.IsZero() {
+				if time.Since(passwordSentAt) > 3*time.Second {
+					return ErrWrongRoomPassword
+				}
+				break
+			}
+			log.Printf("Бот %s: комната защищена паролем, вводим пароль", bot.ID)
+			bot.Events.Add("info", "room password requested")
+			err := chromedp.Run(ctx,
+				chromedp.SendKeys(roomPasswordSelector, bot.RoomPassword+kb.Enter, chromedp.ByQuery),
+			)
+			if err != nil {
+				return err
+			}
+			passwordSentAt = time.Now()
+		}
+
+		if time.Now().After(deadline) {
+			if !lobbySince.IsZero() {
+				return ErrLobbyNotAdmitted
+			}
+			return ErrJoinTimeout
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// knock представляется именем бота и просит допуска в комнату
+func (bot *Bot) knock(ctx context.Context) error {
+	var nameNodes, knockNodes []*cdp.Node
+	err := chromedp.Run(ctx,
+		chromedp.Nodes(lobbyNameSelector, &nameNodes, chromedp.ByQuery, chromedp.AtLeast(0)),
+		chromedp.Nodes(lobbyKnockSelector, &knockNodes, chromedp.ByQuery, chromedp.AtLeast(0)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(nameNodes) > 0 && nameNodes[0].AttributeValue("value") == "" {
+		err = chromedp.Run(ctx, chromedp.SendKeys(lobbyNameSelector, bot.BotName, chromedp.ByQuery))
+		if err != nil {
+			return err
+		}
+	}
+	if len(knockNodes) > 0 {
+		log.Printf("Бот %s: стучимся в лобби", bot.ID)
+		bot.Events.Add("info", "knocking in lobby")
+		return chromedp.Run(ctx, chromedp.Click(lobbyKnockSelector, chromedp.ByQuery))
+	}
+	return nil
+}
+
+// enableE2EE включает сквозное шифрование с заданной парольной фразой.
+// Старые версии Jitsi принимают фразу через setE2EEKey; при внешнем
+// управлении ключами ключ получается как SHA-256 от фразы; иначе E2EE
+// включается с автоматическим управлением ключами.
+func (bot *Bot) enableE2EE(ctx context.Context) error {
+	key, _ := json.Marshal(bot.E2EEPassphrase)
+	script := fmt.Sprintf(`(async function(passphrase) {
+		const room = window.APP && APP.conference && APP.conference._room;
+		if (!room) {
+			throw new Error('conference is not ready');
+		}
+		if (room.isE2EESupported && !room.isE2EESupported()) {
+			throw new Error('E2EE is not supported');
+		}
+		const external = window.config && config.e2ee && config.e2ee.externallyManagedKey;
+		if (room.setE2EEKey) {
+			room.setE2EEKey(passphrase);
+		} else if (external && room.setMediaEncryptionKey) {
+			const digest = await crypto.subtle.digest('SHA-256', new TextEncoder().encode(passphrase));
+			await room.setMediaEncryptionKey({ encryptionKey: new Uint8Array(digest), index: 0 });
+		}
+		if (room.toggleE2EE) {
+			room.toggleE2EE(true);
+		}
+		return true;
+	})(%s)`, key)
+
+	var ok bool
+	if err := chromedp.Run(ctx, chromedp.Evaluate(script, &ok, evalAwait)); err != nil {
+		return fmt.Errorf("failed to enable E2EE: %v", err)
+	}
+	bot.Events.Add("info", "e2ee enabled")
+	log.Printf("Бот %s: E2EE включено", bot.ID)
+	return nil
+}
+
+// fail завершает неудачный запуск: сохраняет ошибку, закрывает браузер и
+// выставляет статус, соответствующий ошибке
+func (bot *Bot) fail(err error) error {
+	// Запуск прерван вызовом Stop - это не ошибка
+	if s := bot.GetStatus(); s == StatusStopping || s == StatusStopped {
+		bot.cancelContexts()
+		return err
+	}
+	// Браузер пула упал во время входа - бот будет перезапущен в другом
+	if bot.migrateIfBrowserLost() {
+		return err
+	}
+
+	status := StatusStopped
+	if errors.Is(err, ErrRoomPasswordRequired) || errors.Is(err, ErrWrongRoomPassword) {
+		status = StatusWrongPassword
+	}
+
+	log.Printf("Бот %s (%s) не запустился: %v", bot.BotName, bot.ID, err)
+	bot.SetError(err)
+	bot.cancelContexts()
+	bot.SetStatus(status)
+	return err
+}
+
+// SetError сохраняет последнюю ошибку бота (nil сбрасывает её)
+func (bot *Bot) SetError(err error) {
+	bot.mu.Lock()
+	bot.lastError = ""
+	if err != nil {
+		bot.lastError = err.Error()
+	}
+	bot.mu.Unlock()
+
+	if err != nil {
+		bot.Events.Add("error", err.Error())
+	}
+}
+
+// GetError возвращает текст последней ошибки бота
+func (bot *Bot) GetError() string {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	return bot.lastError
+}
+
+// isIdle сообщает, что бот не работает и его можно запустить
+func (bot *Bot) isIdle() bool {
+	switch bot.GetStatus() {
+	case StatusStopped, StatusWrongPassword:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package ssjitsi
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBotFail(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string // Статус бота до ошибки
+		err       error
+		want      string
+		wantError bool // Ошибка сохраняется в боте
+	}{
+		{"password required", StatusStarting, ErrRoomPasswordRequired, StatusWrongPassword, true},
+		{"wrong password", StatusStarting, ErrWrongRoomPassword, StatusWrongPassword, true},
+		{"wrapped wrong password", StatusStarting, fmt.Errorf("rejoin failed: %w", ErrWrongRoomPassword), StatusWrongPassword, true},
+		{"join timeout", StatusStarting, ErrJoinTimeout, StatusStopped, true},
+		{"lobby rejection", StatusInLobby, ErrLobbyRejected, StatusStopped, true},
+		{"stopped while starting", StatusStopping, errors.New("context canceled"), StatusStopping, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cancelled := false
+			bot := &Bot{ID: "bot", Status: tt.status, CtxCancel: func() { cancelled = true }}
+			if err := bot.fail(tt.err); err != tt.err {
+				t.Errorf("fail() = %v, want %v", err, tt.err)
+			}
+			if got := bot.GetStatus(); got != tt.want {
+				t.Errorf("status = %q, want %q", got, tt.want)
+			}
+			if got := bot.GetError(); (got != "") != tt.wantError {
+				t.Errorf("error = %q, want saved: %v", got, tt.wantError)
+			}
+			if !cancelled || bot.CtxCancel != nil {
+				t.Error("browser context is not cancelled")
+			}
+		})
+	}
+}
+
+func TestJoinTimeouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		bot       *Bot
+		wantJoin  time.Duration
+		wantLobby time.Duration
+	}{
+		{"defaults", &Bot{}, defaultJoinTimeout, defaultLobbyTimeout},
+		{"configured", &Bot{JoinTimeout: 2 * time.Minute, LobbyTimeout: 30 * time.Minute}, 2 * time.Minute, 30 * time.Minute},
+		{"negative values use defaults", &Bot{JoinTimeout: -time.Second, LobbyTimeout: -time.Second}, defaultJoinTimeout, defaultLobbyTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bot.joinTimeout(); got != tt.wantJoin {
+				t.Errorf("joinTimeout() = %s, want %s", got, tt.wantJoin)
+			}
+			if got := tt.bot.lobbyTimeout(); got != tt.wantLobby {
+				t.Errorf("lobbyTimeout() = %s, want %s", got, tt.wantLobby)
+			}
+		})
+	}
+}
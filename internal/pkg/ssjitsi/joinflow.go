@@ -0,0 +1,375 @@
+package ssjitsi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/chromedp"
+)
+
+// Таймаут шага входа по умолчанию
+const defaultStepTimeout = 30 * time.Second
+
+// JoinStep описывает один шаг сценария входа в комнату.
+// Строковые поля - шаблоны text/template с данными JoinData.
+type JoinStep struct {
+	Name       string        `yaml:"Name"`
+	Action     string        `yaml:"Action"`     // navigate, wait, click, type, permission, eval, sleep
+	URL        string        `yaml:"URL"`        // navigate: адрес перехода
+	Selector   string        `yaml:"Selector"`   // wait, click, type: CSS селектор
+	Text       string        `yaml:"Text"`       // type: вводимый текст, поле предварительно очищается
+	Script     string        `yaml:"Script"`     // eval: JS выражение, должно вернуть истину; wait: условие ожидания
+	Permission string        `yaml:"Permission"` // permission: microphone, camera и т.д.
+	Setting    string        `yaml:"Setting"`    // permission: granted, denied, prompt
+	Duration   time.Duration `yaml:"Duration"`   // sleep: длительность паузы
+	When       string        `yaml:"When"`       // JS условие: шаг выполняется, только если оно истинно
+	Timeout    time.Duration `yaml:"Timeout"`    // Таймаут шага (по умолчанию 30s)
+	Optional   bool          `yaml:"Optional"`   // Ошибка шага не прерывает вход
+}
+
+// JoinData - данные для шаблонов шагов входа
+type JoinData struct {
+	Server       string
+	Room         string
+	BotName      string
+	Username     string
+	Pass         string
+	RoomPassword string
+	Token        string
+	MeetingURL   string
+}
+
+// Встроенные сценарии входа для авторизации по токену и через формы.
+// Оба открывают комнату по прямой ссылке, чтобы в адрес попали
+// переопределения config и interfaceConfig.
+var builtinJoinProfiles = map[string][]JoinStep{
+	"jwt": {
+		{Name: "open meeting", Action: "navigate", URL: "{{.MeetingURL}}"},
+		{Name: "deny microphone", Action: "permission", Permission: "microphone", Setting: "denied"},
+		{Name: "page load", Action: "sleep", Duration: 2 * time.Second},
+		{Name: "join", Action: "click", Selector: `[aria-label="Join meeting"]`},
+		{Name: "connect", Action: "sleep", Duration: 2 * time.Second},
+	},
+	"form": {
+		{Name: "open meeting", Action: "navigate", URL: "{{.MeetingURL}}"},
+		{Name: "deny microphone", Action: "permission", Permission: "microphone", Setting: "denied"},
+		{Name: "page load", Action: "sleep", Duration: time.Second},
+		{Name: "enter name", Action: "type", Selector: `[aria-label="Enter your name"]`, Text: "{{.BotName}}"},
+		{Name: "join", Action: "click", Selector: `[aria-label="Join meeting"]`},
+		{Name: "join again", Action: "click", Selector: `[aria-label="Join meeting"]`, Timeout: 5 * time.Second, Optional: true},
+		{Name: "connect", Action: "sleep", Duration: 2 * time.Second},
+		{Name: "login username", Action: "type", Selector: "#login-dialog-username", Text: "{{.Username}}", When: loginDialogShown},
+		{Name: "login password", Action: "type", Selector: "#login-dialog-password", Text: "{{.Pass}}", When: loginDialogShown},
+		{Name: "login", Action: "click", Selector: `[aria-label="Login"]`, When: loginDialogShown},
+		{Name: "after login", Action: "sleep", Duration: time.Second, When: loginDialogShown},
+	},
+}
+
+const loginDialogShown = `!!document.querySelector('#login-dialog-username')`
+
+// validateJoinProfile проверяет шаги сценария
+func validateJoinProfile(name string, steps []JoinStep) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("join profile %q has no steps", name)
+	}
+	for i, step := range steps {
+		var missing string
+		switch step.Action {
+		case "navigate":
+			if step.URL == "" {
+				missing = "URL"
+			}
+		case "click", "type":
+			if step.Selector == "" {
+				missing = "Selector"
+			}
+		case "wait":
+			if step.Selector == "" && step.Script == "" {
+				missing = "Selector or Script"
+			}
+		case "permission":
+			if step.Permission == "" {
+				missing = "Permission"
+			}
+		case "eval":
+			if step.Script == "" {
+				missing = "Script"
+			}
+		case "sleep":
+		default:
+			return fmt.Errorf("join profile %q step %d: unknown action %q", name, i+1, step.Action)
+		}
+		if missing != "" {
+			return fmt.Errorf("join profile %q step %d (%s): %s required", name, i+1, step.Action, missing)
+		}
+		for _, tpl := range []string{step.URL, step.Selector, step.Text, step.Script, step.When} {
+			if _, err := template.New("").Parse(tpl); err != nil {
+				return fmt.Errorf("join profile %q step %d: %v", name, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// joinProfileName возвращает имя сценария входа бота
+func (bot *Bot) joinProfileName() string {
+	if bot.JoinProfile != "" {
+		return bot.JoinProfile
+	}
+	switch getAuthMethod(bot) {
+	case "JWT", "TokenService":
+		return "jwt"
+	}
+	return "form"
+}
+
+// joinSteps возвращает шаги сценария входа бота; сценарии из конфигурации
+// перекрывают встроенные с тем же именем
+func (bot *Bot) joinSteps() ([]JoinStep, error) {
+	name := bot.joinProfileName()
+	if steps, ok := bot.joinProfiles[name]; ok {
+		return steps, nil
+	}
+	if steps, ok := builtinJoinProfiles[name]; ok {
+		return steps, nil
+	}
+	return nil, fmt.Errorf("unknown join profile %q", name)
+}
+
+// joinData собирает данные для шаблонов шагов
+func (bot *Bot) joinData(token string) JoinData {
+	return JoinData{
+		Server:       bot.JitsiServer,
+		Room:         bot.Room,
+		BotName:      bot.BotName,
+		Username:     bot.Username,
+		Pass:         bot.Pass,
+		RoomPassword: bot.RoomPassword,
+		Token:        token,
+		MeetingURL:   bot.meetingURL(token),
+	}
+}
+
+// maskToken скрывает токен для журнала
+func maskToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	return "***"
+}
+
+// renderedStep - шаг с подставленными шаблонами
+type renderedStep struct {
+	JoinStep
+	maskedURL string // URL для журнала, без токена
+}
+
+func renderTemplate(tpl string, data JoinData) (string, error) {
+	if !strings.Contains(tpl, "{{") {
+		return tpl, nil
+	}
+	t, err := template.New("").Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func renderStep(step JoinStep, data, masked JoinData) (renderedStep, error) {
+	r := renderedStep{JoinStep: step}
+	var err error
+	for _, f := range []*string{&r.URL, &r.Selector, &r.Text, &r.Script, &r.When} {
+		if *f, err = renderTemplate(*f, data); err != nil {
+			return r, err
+		}
+	}
+	r.maskedURL, err = renderTemplate(step.URL, masked)
+	return r, err
+}
+
+// describe возвращает описание шага для журнала; секреты не выводятся
+func (r renderedStep) describe() string {
+	switch r.Action {
+	case "navigate":
+		return fmt.Sprintf("navigate %s", r.maskedURL)
+	case "click":
+		return fmt.Sprintf("click %s", r.Selector)
+	case "type":
+		return fmt.Sprintf("type %s (%d chars)", r.Selector, len([]rune(r.Text)))
+	case "wait":
+		if r.Selector != "" {
+			return fmt.Sprintf("wait %s", r.Selector)
+		}
+		return fmt.Sprintf("wait until %s", r.Script)
+	case "permission":
+		return fmt.Sprintf("permission %s=%s", r.Permission, r.Setting)
+	case "eval":
+		return fmt.Sprintf("eval %s", r.Script)
+	case "sleep":
+		return fmt.Sprintf("sleep %s", r.Duration)
+	}
+	return r.Action
+}
+
+// runJoinFlow выполняет шаги сценария входа в комнату
+func (bot *Bot) runJoinFlow(ctx context.Context, token string) error {
+	steps, err := bot.joinSteps()
+	if err != nil {
+		return err
+	}
+
+	name := bot.joinProfileName()
+	log.Printf("Бот %s: сценарий входа %q (%d шагов)", bot.ID, name, len(steps))
+	data, masked := bot.joinData(token), bot.joinData(maskToken(token))
+
+	for i, step := range steps {
+		r, err := renderStep(step, data, masked)
+		if err != nil {
+			return fmt.Errorf("join step %d (%s): %v", i+1, step.Name, err)
+		}
+
+		trace := fmt.Sprintf("join step %d/%d %q: %s", i+1, len(steps), r.Name, r.describe())
+		started := time.Now()
+		skipped, err := bot.runJoinStep(ctx, r)
+		elapsed := time.Since(started).Round(time.Millisecond)
+
+		switch {
+		case err != nil && r.Optional:
+			bot.traceJoin(fmt.Sprintf("%s - failed, optional (%s): %v", trace, elapsed, err))
+		case err != nil:
+			bot.traceJoin(fmt.Sprintf("%s - failed (%s): %v", trace, elapsed, err))
+			return fmt.Errorf("join step %d (%s): %v", i+1, r.Name, err)
+		case skipped:
+			bot.traceJoin(trace + " - skipped")
+		default:
+			bot.traceJoin(fmt.Sprintf("%s - ok (%s)", trace, elapsed))
+		}
+	}
+	return nil
+}
+
+// traceJoin пишет трассировку шага в журнал событий и, если включено, в лог
+func (bot *Bot) traceJoin(msg string) {
+	bot.Events.Add("join", msg)
+	if bot.JoinTrace {
+		log.Printf("Бот %s: %s", bot.ID, msg)
+	}
+}
+
+// runJoinStep выполняет один шаг; возвращает true, если шаг пропущен по условию when
+func (bot *Bot) runJoinStep(ctx context.Context, step renderedStep) (bool, error) {
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = defaultStepTimeout
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if step.When != "" {
+		var ok bool
+		if err := chromedp.Run(stepCtx, chromedp.Evaluate("!!("+step.When+")", &ok)); err != nil {
+			return false, err
+		}
+		if !ok {
+			return true, nil
+		}
+	}
+
+	switch step.Action {
+	case "navigate":
+		return false, chromedp.Run(stepCtx, chromedp.Navigate(step.URL))
+	case "click":
+		return false, chromedp.Run(stepCtx, chromedp.Click(step.Selector, chromedp.ByQuery))
+	case "type":
+		// Поле может быть уже заполнено, например именем из адреса комнаты
+		return false, chromedp.Run(stepCtx,
+			chromedp.SetValue(step.Selector, "", chromedp.ByQuery),
+			chromedp.SendKeys(step.Selector, step.Text, chromedp.ByQuery),
+		)
+	case "wait":
+		if step.Selector != "" {
+			return false, chromedp.Run(stepCtx, chromedp.WaitVisible(step.Selector, chromedp.ByQuery))
+		}
+		return false, chromedp.Run(stepCtx, chromedp.Poll("!!("+step.Script+")", nil,
+			chromedp.WithPollingInterval(250*time.Millisecond), chromedp.WithPollingTimeout(0)))
+	case "permission":
+		setting := browser.PermissionSetting(step.Setting)
+		if setting == "" {
+			setting = browser.PermissionSettingDenied
+		}
+		// Подмененный микрофон не трогает устройство, но Jitsi не станет
+		// его запрашивать при запрещенном доступе
+		if step.Permission == "microphone" && bot.Announcement.enabled() {
+			setting = browser.PermissionSettingGranted
+		}
+		params := &browser.SetPermissionParams{
+			Permission: &browser.PermissionDescriptor{Name: step.Permission},
+			Setting:    setting,
+		}
+		return false, chromedp.Run(stepCtx, params)
+	case "eval":
+		var ok bool
+		if err := chromedp.Run(stepCtx, chromedp.Evaluate("!!("+step.Script+")", &ok, evalAwait)); err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, fmt.Errorf("condition is false: %s", step.Script)
+		}
+		return false, nil
+	case "sleep":
+		// Пауза не ограничивается таймаутом шага
+		return false, chromedp.Run(ctx, chromedp.Sleep(step.Duration))
+	}
+	return false, fmt.Errorf("unknown action %q", step.Action)
+}
+
+// DryRunJoin выводит шаги сценария входа бота с подставленными значениями,
+// не запуская браузер. Токен и пароли не выводятся.
+func (bot *Bot) DryRunJoin(w io.Writer) error {
+	steps, err := bot.joinSteps()
+	if err != nil {
+		return err
+	}
+
+	token := ""
+	switch getAuthMethod(bot) {
+	case "JWT", "TokenService":
+		token = "***"
+	}
+	masked := bot.joinData(token)
+	masked.Pass, masked.RoomPassword = "***", "***"
+	fmt.Fprintf(w, "Бот %q (комната %q): сценарий входа %q\n", bot.BotName, bot.Room, bot.joinProfileName())
+	for i, step := range steps {
+		r, err := renderStep(step, masked, masked)
+		if err != nil {
+			return fmt.Errorf("join step %d (%s): %v", i+1, step.Name, err)
+		}
+		line := fmt.Sprintf("  %2d. %-16s %s", i+1, r.Name, r.describe())
+		if r.When != "" {
+			line += fmt.Sprintf(" [when %s]", r.When)
+		}
+		if r.Optional {
+			line += " [optional]"
+		}
+		if r.Action != "sleep" {
+			timeout := r.Timeout
+			if timeout <= 0 {
+				timeout = defaultStepTimeout
+			}
+			line += fmt.Sprintf(" [timeout %s]", timeout)
+		}
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
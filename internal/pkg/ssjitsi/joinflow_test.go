@@ -0,0 +1,138 @@
+package ssjitsi
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestValidateJoinProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []JoinStep
+		wantErr string
+	}{
+		{"no steps", nil, "has no steps"},
+		{"unknown action", []JoinStep{{Action: "scroll"}}, `unknown action "scroll"`},
+		{"navigate without URL", []JoinStep{{Action: "navigate"}}, "URL required"},
+		{"click without selector", []JoinStep{{Action: "click"}}, "Selector required"},
+		{"type without selector", []JoinStep{{Action: "type", Text: "x"}}, "Selector required"},
+		{"wait without condition", []JoinStep{{Action: "wait"}}, "Selector or Script required"},
+		{"permission without name", []JoinStep{{Action: "permission"}}, "Permission required"},
+		{"eval without script", []JoinStep{{Action: "eval"}}, "Script required"},
+		{"broken template", []JoinStep{{Action: "navigate", URL: "{{.MeetingURL"}}, "step 1"},
+		{"error points to the step", []JoinStep{{Action: "sleep"}, {Action: "click"}}, "step 2 (click)"},
+		{"valid steps", []JoinStep{
+			{Action: "navigate", URL: "{{.MeetingURL}}"},
+			{Action: "wait", Script: "APP.conference.isJoined()"},
+			{Action: "wait", Selector: "#name"},
+			{Action: "type", Selector: "#name", Text: "{{.BotName}}"},
+			{Action: "permission", Permission: "microphone"},
+			{Action: "eval", Script: "true"},
+			{Action: "sleep"},
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJoinProfile("test", tt.steps)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateJoinProfile() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	for name, steps := range builtinJoinProfiles {
+		if err := validateJoinProfile(name, steps); err != nil {
+			t.Errorf("built-in profile: %v", err)
+		}
+	}
+}
+
+func TestRenderStep(t *testing.T) {
+	data := JoinData{BotName: "Recorder", Room: "daily", Pass: "p@ss", Token: "secret-token", MeetingURL: "https://meet.example.com/daily?jwt=secret-token"}
+	masked := data
+	masked.Token, masked.MeetingURL = "***", "https://meet.example.com/daily?jwt=***"
+
+	tests := []struct {
+		name     string
+		step     JoinStep
+		want     JoinStep
+		describe string
+	}{
+		{
+			name:     "navigate hides the token",
+			step:     JoinStep{Action: "navigate", URL: "{{.MeetingURL}}"},
+			want:     JoinStep{Action: "navigate", URL: data.MeetingURL},
+			describe: "navigate https://meet.example.com/daily?jwt=***",
+		},
+		{
+			name:     "typed text is not logged",
+			step:     JoinStep{Action: "type", Selector: "#password", Text: "{{.Pass}}"},
+			want:     JoinStep{Action: "type", Selector: "#password", Text: "p@ss"},
+			describe: "type #password (4 chars)",
+		},
+		{
+			name:     "selector and condition",
+			step:     JoinStep{Action: "click", Selector: `[aria-label="{{.Room}}"]`, When: "!!'{{.BotName}}'"},
+			want:     JoinStep{Action: "click", Selector: `[aria-label="daily"]`, When: "!!'Recorder'"},
+			describe: `click [aria-label="daily"]`,
+		},
+		{
+			name:     "text without templates",
+			step:     JoinStep{Action: "wait", Script: "1 < 2"},
+			want:     JoinStep{Action: "wait", Script: "1 < 2"},
+			describe: "wait until 1 < 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := renderStep(tt.step, data, masked)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.JoinStep != tt.want {
+				t.Errorf("renderStep() = %+v, want %+v", r.JoinStep, tt.want)
+			}
+			if got := r.describe(); got != tt.describe {
+				t.Errorf("describe() = %q, want %q", got, tt.describe)
+			}
+		})
+	}
+
+	if _, err := renderStep(JoinStep{Action: "type", Text: "{{.Unknown}}"}, data, masked); err == nil {
+		t.Error("renderStep() with an unknown field succeeded, want error")
+	}
+}
+
+func TestJoinStepYAML(t *testing.T) {
+	var steps []JoinStep
+	err := yaml.Unmarshal([]byte(`
+- Name: join
+  Action: click
+  Selector: '#join'
+  When: "true"
+  Timeout: 20s
+  Optional: true
+- Name: wait
+  Action: sleep
+  Duration: 1500ms
+`), &steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []JoinStep{
+		{Name: "join", Action: "click", Selector: "#join", When: "true", Timeout: 20 * time.Second, Optional: true},
+		{Name: "wait", Action: "sleep", Duration: 1500 * time.Millisecond},
+	}
+	if len(steps) != len(want) || steps[0] != want[0] || steps[1] != want[1] {
+		t.Errorf("steps = %+v, want %+v", steps, want)
+	}
+}
@@ -0,0 +1,227 @@
+package ssjitsi
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Время жизни токена по умолчанию
+const defaultJWTTTL = 2 * time.Hour
+
+// JWTOptions задает дополнительные параметры JWT токена бота
+type JWTOptions struct {
+	TTL      time.Duration          `yaml:"TTL"`      // Время жизни токена (по умолчанию 2h)
+	Subject  string                 `yaml:"Subject"`  // Переопределение sub (по умолчанию домен Jitsi сервера)
+	Audience string                 `yaml:"Audience"` // Переопределение aud (по умолчанию JWTAppID)
+	User     map[string]interface{} `yaml:"User"`     // Дополнительные поля context.user: id, email, avatar, moderator
+	Features map[string]interface{} `yaml:"Features"` // Поля context.features: recording, livestreaming и т.д.
+	Claims   map[string]interface{} `yaml:"Claims"`   // Произвольные дополнительные claims
+
+	// Асимметричная подпись: если задан ключ, JWTAppSecret не используется
+	Algorithm      string   `yaml:"Algorithm"`      // HS256 (по умолчанию), RS256, ES256 и т.д.
+	KeyID          string   `yaml:"KeyID"`          // kid в заголовке токена
+	PrivateKey     string   `yaml:"PrivateKey"`     // PEM закрытого ключа
+	PrivateKeyFile string   `yaml:"PrivateKeyFile"` // Путь к PEM файлу закрытого ключа
+	Keys           []JWTKey `yaml:"Keys"`           // Набор ключей для ротации
+}
+
+// JWTKey описывает ключ подписи для ротации. Активным считается ключ
+// с самой поздней датой ValidFrom, которая уже наступила.
+type JWTKey struct {
+	KeyID          string `yaml:"KeyID"`
+	Algorithm      string `yaml:"Algorithm"`
+	PrivateKey     string `yaml:"PrivateKey"`
+	PrivateKeyFile string `yaml:"PrivateKeyFile"`
+	ValidFrom      string `yaml:"ValidFrom"` // Дата начала действия: 2006-01-02 или RFC3339
+}
+
+// hasKey сообщает, настроена ли асимметричная подпись
+func (o JWTOptions) hasKey() bool {
+	return o.PrivateKey != "" || o.PrivateKeyFile != "" || len(o.Keys) > 0
+}
+
+// activeKey выбирает ключ подписи на момент now
+func (o JWTOptions) activeKey(now time.Time) (JWTKey, error) {
+	if len(o.Keys) == 0 {
+		return JWTKey{
+			KeyID:          o.KeyID,
+			Algorithm:      o.Algorithm,
+			PrivateKey:     o.PrivateKey,
+			PrivateKeyFile: o.PrivateKeyFile,
+		}, nil
+	}
+
+	var active *JWTKey
+	var activeFrom time.Time
+	for i := range o.Keys {
+		from, err := parseKeyDate(o.Keys[i].ValidFrom)
+		if err != nil {
+			return JWTKey{}, fmt.Errorf("key %q: %v", o.Keys[i].KeyID, err)
+		}
+		if from.After(now) {
+			continue
+		}
+		if active == nil || from.After(activeFrom) {
+			active = &o.Keys[i]
+			activeFrom = from
+		}
+	}
+	if active == nil {
+		return JWTKey{}, fmt.Errorf("no JWT key is valid at %s", now.Format(time.RFC3339))
+	}
+
+	key := *active
+	if key.Algorithm == "" {
+		key.Algorithm = o.Algorithm
+	}
+	return key, nil
+}
+
+func parseKeyDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid ValidFrom %q", s)
+	}
+	return t, nil
+}
+
+// signingKey возвращает метод подписи, ключ и kid для токена
+func (o JWTOptions) signingKey(appSecret string, now time.Time) (jwt.SigningMethod, interface{}, string, error) {
+	if !o.hasKey() {
+		alg := o.Algorithm
+		if alg == "" {
+			alg = "HS256"
+		}
+		method := jwt.GetSigningMethod(alg)
+		if _, ok := method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, nil, "", fmt.Errorf("algorithm %s requires a private key", alg)
+		}
+		return method, []byte(appSecret), o.KeyID, nil
+	}
+
+	key, err := o.activeKey(now)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	pemData := []byte(key.PrivateKey)
+	if key.PrivateKeyFile != "" {
+		pemData, err = os.ReadFile(key.PrivateKeyFile)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("failed to read private key: %v", err)
+		}
+	}
+
+	alg := key.Algorithm
+	if alg == "" {
+		alg = "RS256"
+		if _, err := jwt.ParseECPrivateKeyFromPEM(pemData); err == nil {
+			alg = "ES256"
+		}
+	}
+
+	method := jwt.GetSigningMethod(alg)
+	var signKey interface{}
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		signKey, err = jwt.ParseRSAPrivateKeyFromPEM(pemData)
+	case *jwt.SigningMethodECDSA:
+		signKey, err = jwt.ParseECPrivateKeyFromPEM(pemData)
+	default:
+		return nil, nil, "", fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to parse private key: %v", err)
+	}
+
+	return method, signKey, key.KeyID, nil
+}
+
+// ttl возвращает время жизни токена с учетом значения по умолчанию
+func (o JWTOptions) ttl() time.Duration {
+	if o.TTL > 0 {
+		return o.TTL
+	}
+	return defaultJWTTTL
+}
+
+// GenerateJitsiJWT генерирует JWT токен для авторизации в Jitsi Meet
+func GenerateJitsiJWT(appID, appSecret, jitsiServer, room, userName string, opts JWTOptions) (string, error) {
+	// Извлекаем домен из URL сервера Jitsi
+	parsedURL, err := url.Parse(jitsiServer)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Jitsi server URL: %v", err)
+	}
+	domain := parsedURL.Hostname()
+
+	sub := domain
+	if opts.Subject != "" {
+		sub = opts.Subject
+	}
+	aud := appID
+	if opts.Audience != "" {
+		aud = opts.Audience
+	}
+
+	user := map[string]interface{}{}
+	for k, v := range normalizeYAMLMap(opts.User) {
+		user[k] = v
+	}
+	user["name"] = userName
+
+	jwtContext := map[string]interface{}{
+		"user": user,
+	}
+	if len(opts.Features) > 0 {
+		jwtContext["features"] = normalizeYAMLMap(opts.Features)
+	}
+
+	// Создаем claims для токена
+	now := time.Now()
+	claims := jwt.MapClaims{}
+	// Дополнительные claims задаются первыми, чтобы не перекрывать обязательные
+	for k, v := range normalizeYAMLMap(opts.Claims) {
+		claims[k] = v
+	}
+	claims["iss"] = appID                      // Issuer - идентификатор приложения
+	claims["aud"] = aud                        // Audience - идентификатор приложения
+	claims["sub"] = sub                        // Subject - домен Jitsi сервера
+	claims["room"] = room                      // Имя комнаты
+	claims["exp"] = now.Add(opts.ttl()).Unix() // Expiration - время жизни токена
+	claims["nbf"] = now.Unix()                 // Not Before - токен действителен с текущего момента
+	claims["context"] = jwtContext
+
+	// Выбираем алгоритм и ключ: HS256 с секретом или асимметричный ключ
+	method, key, kid, err := opts.signingKey(appSecret, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to select JWT signing key: %v", err)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	// Подписываем токен
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT token: %v", err)
+	}
+
+	return tokenString, nil
+}
+
+// jwtToken генерирует свежий токен для бота
+func (bot *Bot) jwtToken() (string, error) {
+	return GenerateJitsiJWT(bot.JWTAppID, bot.JWTAppSecret, bot.JitsiServer, bot.Room, bot.BotName, bot.JWT)
+}
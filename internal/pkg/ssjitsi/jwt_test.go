@@ -0,0 +1,158 @@
+package ssjitsi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaKeyPEM(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, string(data)
+}
+
+func ecKeyPEM(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestJWTActiveKey(t *testing.T) {
+	opts := JWTOptions{
+		Algorithm: "RS256",
+		Keys: []JWTKey{
+			{KeyID: "old", ValidFrom: "2024-01-01"},
+			{KeyID: "future", ValidFrom: "2030-01-01"},
+			{KeyID: "current", ValidFrom: "2025-06-01T12:00:00Z", Algorithm: "ES256"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		opts    JWTOptions
+		now     string
+		kid     string
+		alg     string
+		wantErr bool
+	}{
+		{"before any key", opts, "2023-12-31T00:00:00Z", "", "", true},
+		{"first key", opts, "2024-01-01T00:00:00Z", "old", "RS256", false},
+		{"just before rotation", opts, "2025-06-01T11:59:59Z", "old", "RS256", false},
+		{"after rotation", opts, "2025-06-01T12:00:00Z", "current", "ES256", false},
+		{"future key is not active yet", opts, "2029-12-31T23:59:59Z", "current", "ES256", false},
+		{"future key", opts, "2030-01-01T00:00:00Z", "future", "RS256", false},
+		{"empty ValidFrom is always valid", JWTOptions{Keys: []JWTKey{{KeyID: "a"}}}, "2000-01-01T00:00:00Z", "a", "", false},
+		{"invalid ValidFrom", JWTOptions{Keys: []JWTKey{{KeyID: "a", ValidFrom: "01.01.2024"}}}, "2025-01-01T00:00:00Z", "", "", true},
+		{"single key without rotation", JWTOptions{KeyID: "k", Algorithm: "ES256", PrivateKey: "pem"}, "2025-01-01T00:00:00Z", "k", "ES256", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, err := tt.opts.activeKey(now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("activeKey() = %q, want error", key.KeyID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.KeyID != tt.kid || key.Algorithm != tt.alg {
+				t.Errorf("activeKey() = %s/%s, want %s/%s", key.KeyID, key.Algorithm, tt.kid, tt.alg)
+			}
+		})
+	}
+}
+
+func TestGenerateJitsiJWT(t *testing.T) {
+	rsaKey, rsaPEM := rsaKeyPEM(t)
+	ecKey, ecPEM := ecKeyPEM(t)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		opts    JWTOptions
+		verify  interface{}
+		alg     string
+		kid     string
+		wantErr bool
+	}{
+		{"HS256 with secret", JWTOptions{}, []byte("secret"), "HS256", "", false},
+		{"HS256 with kid", JWTOptions{KeyID: "hs"}, []byte("secret"), "HS256", "hs", false},
+		{"custom TTL", JWTOptions{TTL: 30 * time.Minute}, []byte("secret"), "HS256", "", false},
+		{"HS384", JWTOptions{Algorithm: "HS384"}, []byte("secret"), "HS384", "", false},
+		{"RS256 without key", JWTOptions{Algorithm: "RS256"}, nil, "", "", true},
+		{"RS256 key", JWTOptions{KeyID: "rsa", PrivateKey: rsaPEM}, &rsaKey.PublicKey, "RS256", "rsa", false},
+		{"ES256 detected from key", JWTOptions{KeyID: "ec", PrivateKey: ecPEM}, &ecKey.PublicKey, "ES256", "ec", false},
+		{"algorithm does not match key", JWTOptions{Algorithm: "ES256", PrivateKey: rsaPEM}, nil, "", "", true},
+		{"rotation picks the key already in effect", JWTOptions{Keys: []JWTKey{
+			{KeyID: "rsa", PrivateKey: rsaPEM, ValidFrom: past},
+			{KeyID: "ec", PrivateKey: ecPEM, ValidFrom: future},
+		}}, &rsaKey.PublicKey, "RS256", "rsa", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Claims = map[string]interface{}{"room": "overridden", "tenant": "acme"}
+			tt.opts.User = map[string]interface{}{"moderator": true}
+			signed, err := GenerateJitsiJWT("app", "secret", "https://meet.example.com", "Room 1", "Recorder", tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GenerateJitsiJWT() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+				return tt.verify, nil
+			}, jwt.WithValidMethods([]string{tt.alg}))
+			if err != nil {
+				t.Fatalf("token does not verify: %v", err)
+			}
+			if kid, _ := token.Header["kid"].(string); kid != tt.kid {
+				t.Errorf("kid = %q, want %q", kid, tt.kid)
+			}
+
+			claims := token.Claims.(jwt.MapClaims)
+			want := map[string]string{"iss": "app", "aud": "app", "sub": "meet.example.com", "room": "Room 1", "tenant": "acme"}
+			for k, v := range want {
+				if claims[k] != v {
+					t.Errorf("claim %s = %v, want %q", k, claims[k], v)
+				}
+			}
+			if exp, nbf := claims["exp"].(float64), claims["nbf"].(float64); time.Duration(exp-nbf)*time.Second != tt.opts.ttl() {
+				t.Errorf("token lifetime = %vs, want %s", exp-nbf, tt.opts.ttl())
+			}
+			user := claims["context"].(map[string]interface{})["user"].(map[string]interface{})
+			if user["name"] != "Recorder" || user["moderator"] != true {
+				t.Errorf("context.user = %v", user)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package ssjitsi
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// StartupOptions ограничивает одновременный запуск ботов
+type StartupOptions struct {
+	MaxConcurrentStarts int           `yaml:"MaxConcurrentStarts"` // Сколько ботов могут входить в комнаты одновременно; 0 - без ограничения
+	MaxRunningBots      int           `yaml:"MaxRunningBots"`      // Сколько ботов могут работать одновременно; 0 - без ограничения
+	StartInterval       time.Duration `yaml:"StartInterval"`       // Минимальная пауза между запусками
+}
+
+// enabled сообщает, задано ли хотя бы одно ограничение
+func (o StartupOptions) enabled() bool {
+	return o.MaxConcurrentStarts > 0 || o.MaxRunningBots > 0 || o.StartInterval > 0
+}
+
+// Launcher - очередь запуска ботов с ограничениями на число одновременных
+// запусков и работающих ботов
+type Launcher struct {
+	opts StartupOptions
+
+	mu        sync.Mutex
+	queue     []*Bot
+	starting  int
+	running   int
+	lastStart time.Time
+	changed   chan struct{} // Закрывается при каждом изменении состояния очереди
+}
+
+// launchSlot - разрешение на запуск, полученное ботом из очереди
+type launchSlot struct {
+	launcher *Launcher
+	starting bool
+	running  bool
+}
+
+// NewLauncher создает очередь запуска
+func NewLauncher(opts StartupOptions) *Launcher {
+	return &Launcher{opts: opts, changed: make(chan struct{})}
+}
+
+// notifyLocked будит ожидающих ботов. Вызывается под l.mu.
+func (l *Launcher) notifyLocked() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// canStartLocked проверяет лимиты. Вызывается под l.mu.
+func (l *Launcher) canStartLocked() bool {
+	if l.opts.MaxConcurrentStarts > 0 && l.starting >= l.opts.MaxConcurrentStarts {
+		return false
+	}
+	if l.opts.MaxRunningBots > 0 && l.running >= l.opts.MaxRunningBots {
+		return false
+	}
+	return true
+}
+
+// wait ставит бота в очередь и ждет, пока его очередь подойдет и лимиты
+// позволят запуск
+func (l *Launcher) wait(ctx context.Context, bot *Bot) (*launchSlot, error) {
+	l.mu.Lock()
+	l.queue = append(l.queue, bot)
+	l.mu.Unlock()
+
+	for {
+		l.mu.Lock()
+		var delay time.Duration
+		if l.queue[0] == bot && l.canStartLocked() {
+			delay = l.opts.StartInterval - time.Since(l.lastStart)
+			if delay <= 0 {
+				l.queue = l.queue[1:]
+				l.starting++
+				l.running++
+				l.lastStart = time.Now()
+				l.notifyLocked()
+				l.mu.Unlock()
+				return &launchSlot{launcher: l, starting: true, running: true}, nil
+			}
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		// Запуск откладывается - показываем статус queued
+		if bot.GetStatus() == StatusStarting {
+			bot.SetStatus(StatusQueued)
+			log.Printf("Бот %s поставлен в очередь запуска (позиция %d)", bot.ID, l.Position(bot))
+		}
+
+		var timer <-chan time.Time
+		if delay > 0 {
+			timer = time.After(delay)
+		}
+		select {
+		case <-ctx.Done():
+			l.remove(bot)
+			return nil, ctx.Err()
+		case <-changed:
+		case <-timer:
+		}
+	}
+}
+
+// remove убирает бота из очереди
+func (l *Launcher) remove(bot *Bot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, b := range l.queue {
+		if b == bot {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			l.notifyLocked()
+			return
+		}
+	}
+}
+
+// Position возвращает позицию бота в очереди начиная с 1 или 0, если бот
+// не в очереди
+func (l *Launcher) Position(bot *Bot) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, b := range l.queue {
+		if b == bot {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// started освобождает слот одновременного запуска: бот вошел в комнату
+// или остановился
+func (s *launchSlot) started() {
+	if s == nil || !s.starting {
+		return
+	}
+	s.launcher.mu.Lock()
+	defer s.launcher.mu.Unlock()
+	s.starting = false
+	s.launcher.starting--
+	s.launcher.notifyLocked()
+}
+
+// done освобождает все слоты бота после завершения его работы
+func (s *launchSlot) done() {
+	if s == nil {
+		return
+	}
+	s.started()
+	if !s.running {
+		return
+	}
+	s.launcher.mu.Lock()
+	defer s.launcher.mu.Unlock()
+	s.running = false
+	s.launcher.running--
+	s.launcher.notifyLocked()
+}
+
+// waitLaunchSlot ждет разрешения на запуск, если настроена очередь.
+// Stop во время ожидания прерывает его.
+func (bot *Bot) waitLaunchSlot() (*launchSlot, error) {
+	if bot.launcher == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bot.mu.Lock()
+	bot.CtxCancel = cancel
+	bot.mu.Unlock()
+
+	// Stop мог быть вызван до того, как появилась функция отмены
+	if s := bot.GetStatus(); s == StatusStopping || s == StatusStopped {
+		return nil, context.Canceled
+	}
+
+	slot, err := bot.launcher.wait(ctx, bot)
+	if err != nil {
+		return nil, err
+	}
+
+	bot.mu.Lock()
+	bot.CtxCancel = nil
+	bot.mu.Unlock()
+	bot.SetStatus(StatusStarting)
+	return slot, nil
+}
+
+// QueuePosition возвращает позицию бота в очереди запуска или 0
+func (bot *Bot) QueuePosition() int {
+	if bot.launcher == nil {
+		return 0
+	}
+	return bot.launcher.Position(bot)
+}
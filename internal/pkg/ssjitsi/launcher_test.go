@@ -0,0 +1,174 @@
+package ssjitsi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Сколько ждать события, которое должно произойти, и сколько убеждаться,
+// что событие не происходит
+const (
+	launchWait  = time.Second
+	launchQuiet = 30 * time.Millisecond
+)
+
+// enqueue ставит бота в очередь и ждет, пока он займет в ней место или
+// получит слот, чтобы порядок в очереди совпадал с порядком вызовов
+func enqueue(t *testing.T, ctx context.Context, l *Launcher, bot *Bot) <-chan *launchSlot {
+	t.Helper()
+	ch := make(chan *launchSlot, 1)
+	go func() {
+		if slot, err := l.wait(ctx, bot); err == nil {
+			ch <- slot
+		}
+	}()
+	deadline := time.Now().Add(launchWait)
+	for l.Position(bot) == 0 && len(ch) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("bot %s did not enter the queue", bot.ID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return ch
+}
+
+// granted возвращает слоты ботов, которые получили разрешение на запуск
+func granted(waiters []<-chan *launchSlot, slots []*launchSlot) []*launchSlot {
+	deadline := time.After(launchQuiet)
+	for {
+		for i, ch := range waiters {
+			if slots[i] != nil {
+				continue
+			}
+			select {
+			case slots[i] = <-ch:
+			default:
+			}
+		}
+		select {
+		case <-deadline:
+			return slots
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// launched перечисляет номера ботов с полученным слотом
+func launched(slots []*launchSlot) []int {
+	res := make([]int, 0, len(slots))
+	for i, s := range slots {
+		if s != nil {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func TestLauncherLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    StartupOptions
+		bots    int
+		initial []int // Кто запускается сразу
+		started []int // Кто запустится после входа в комнату первого бота
+		done    []int // Кто запустится после остановки первого бота
+	}{
+		{"no limits", StartupOptions{}, 3, []int{0, 1, 2}, []int{0, 1, 2}, []int{0, 1, 2}},
+		{"one start at a time", StartupOptions{MaxConcurrentStarts: 1}, 3, []int{0}, []int{0, 1}, []int{0, 1}},
+		{"two running bots", StartupOptions{MaxRunningBots: 2}, 3, []int{0, 1}, []int{0, 1}, []int{0, 1, 2}},
+		{"both limits", StartupOptions{MaxConcurrentStarts: 1, MaxRunningBots: 2}, 4, []int{0}, []int{0, 1}, []int{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			l := NewLauncher(tt.opts)
+			waiters := make([]<-chan *launchSlot, tt.bots)
+			for i := range waiters {
+				waiters[i] = enqueue(t, ctx, l, &Bot{ID: fmt.Sprintf("bot%d", i)})
+			}
+			slots := make([]*launchSlot, tt.bots)
+
+			check := func(stage string, want []int) {
+				t.Helper()
+				slots = granted(waiters, slots)
+				if got := launched(slots); fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Fatalf("%s: launched bots %v, want %v", stage, got, want)
+				}
+			}
+			check("initial", tt.initial)
+			slots[0].started()
+			check("after started", tt.started)
+			slots[0].done()
+			check("after done", tt.done)
+		})
+	}
+}
+
+func TestLauncherStartInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interval := 100 * time.Millisecond
+	l := NewLauncher(StartupOptions{StartInterval: interval})
+
+	first := enqueue(t, ctx, l, &Bot{ID: "first"})
+	begin := time.Now()
+	second := enqueue(t, ctx, l, &Bot{ID: "second"})
+	for _, ch := range []<-chan *launchSlot{first, second} {
+		select {
+		case <-ch:
+		case <-time.After(launchWait):
+			t.Fatal("bot was not launched")
+		}
+	}
+	if elapsed := time.Since(begin); elapsed < interval-10*time.Millisecond {
+		t.Errorf("second bot launched after %s, want at least %s", elapsed, interval)
+	}
+}
+
+func TestLauncherCancelKeepsOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l := NewLauncher(StartupOptions{MaxConcurrentStarts: 1})
+
+	bots := []*Bot{{ID: "running"}, {ID: "cancelled"}, {ID: "third"}, {ID: "fourth"}}
+	running := enqueue(t, ctx, l, bots[0])
+	cancelCtx, cancelBot := context.WithCancel(ctx)
+	enqueue(t, cancelCtx, l, bots[1])
+	third := enqueue(t, ctx, l, bots[2])
+	enqueue(t, ctx, l, bots[3])
+
+	for i, want := range []int{0, 1, 2, 3} {
+		if got := l.Position(bots[i]); got != want {
+			t.Errorf("Position(%s) = %d, want %d", bots[i].ID, got, want)
+		}
+	}
+
+	cancelBot()
+	deadline := time.Now().Add(launchWait)
+	for l.Position(bots[1]) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("cancelled bot is still in the queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := l.Position(bots[2]); got != 1 {
+		t.Errorf("Position(third) = %d, want 1", got)
+	}
+	if got := l.Position(bots[3]); got != 2 {
+		t.Errorf("Position(fourth) = %d, want 2", got)
+	}
+
+	// Слот освобождается - следующим запускается третий бот, а не четвертый
+	(<-running).started()
+	select {
+	case <-third:
+	case <-time.After(launchWait):
+		t.Fatal("third bot was not launched")
+	}
+	if got := l.Position(bots[3]); got != 1 {
+		t.Errorf("Position(fourth) = %d, want 1", got)
+	}
+}
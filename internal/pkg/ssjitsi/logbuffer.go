@@ -0,0 +1,38 @@
+package ssjitsi
+
+import (
+	"bytes"
+	"sync"
+)
+
+// LogBuffer собирает строки журнала сервера, чтобы отдавать их через API.
+// Подключается через log.SetOutput(io.MultiWriter(os.Stderr, buf)).
+type LogBuffer struct {
+	EventLog
+	mu      sync.Mutex
+	partial []byte
+}
+
+// NewLogBuffer создает буфер на size последних строк
+func NewLogBuffer(size int) *LogBuffer {
+	b := &LogBuffer{}
+	b.EventLog.size = size
+	return b
+}
+
+// Write реализует io.Writer, разбивая поток на строки
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.partial = append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		b.Add("log", string(b.partial[:i]))
+		b.partial = b.partial[i+1:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,183 @@
+package ssjitsi
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Имя файла манифеста в каталоге сессии
+const manifestFile = "session.json"
+
+// SessionManifest описывает сессию записи: кто и с какими параметрами писал
+type SessionManifest struct {
+	ID         string              `json:"id"`
+	Room       string              `json:"room"`
+	BotName    string              `json:"botName"`
+	Server     string              `json:"server"`
+	StartedAt  time.Time           `json:"startedAt"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+	Segments   int                 `json:"segments"`
+	Script     ScriptInfo          `json:"script"`
+	Recording  RecordingManifest   `json:"recording"`
+	Screencast *ScreencastManifest `json:"screencast,omitempty"`
+	Tracks     []TrackInfo         `json:"tracks"`
+	Quality    *QualitySummary     `json:"quality,omitempty"` // Сводка качества соединения по stats.jsonl
+}
+
+// TrackInfo описывает файл дорожки в сессии
+type TrackInfo struct {
+	File      string    `json:"file"`
+	Kind      string    `json:"kind"`   // audio или video
+	Source    string    `json:"source"` // microphone, camera, desktop, mix или screen
+	UserID    string    `json:"userId,omitempty"`
+	User      string    `json:"user,omitempty"`
+	Segment   int       `json:"segment"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// RecordingManifest - запрошенные и фактические параметры записи
+type RecordingManifest struct {
+	TimesliceMs        int64  `json:"timesliceMs"`
+	RequestedMimeType  string `json:"requestedMimeType"`
+	MimeType           string `json:"mimeType"`
+	AudioBitsPerSecond int    `json:"audioBitsPerSecond"`
+	Channels           int    `json:"channels,omitempty"`
+	RoomMix            bool   `json:"roomMix,omitempty"`
+
+	Audio              bool   `json:"audio"`
+	Video              bool   `json:"video"`
+	ScreenShare        bool   `json:"screenShare"`
+	VideoMimeType      string `json:"videoMimeType,omitempty"`
+	VideoBitsPerSecond int    `json:"videoBitsPerSecond,omitempty"`
+	MaxWidth           int    `json:"maxWidth,omitempty"`
+	MaxHeight          int    `json:"maxHeight,omitempty"`
+	MaxFrameRate       int    `json:"maxFrameRate,omitempty"`
+}
+
+// Манифесты разных ботов пишутся в разные каталоги, но один бот может
+// обновлять манифест из нескольких горутин
+var manifestMu sync.Mutex
+
+// resolveSessionDir определяет каталог сессии по имени комнаты, под которым
+// страница отправляет чанки
+func (bot *Bot) resolveSessionDir() {
+	room := strings.ToLower(bot.Room)
+	var pageRoom string
+	err := chromedp.Run(bot.Ctx, chromedp.Evaluate(`(window.APP && APP.conference && APP.conference.roomName) || ''`, &pageRoom))
+	if err == nil && pageRoom != "" {
+		room = pageRoom
+	}
+
+	bot.mu.Lock()
+	bot.sessionDir = filepath.Join(bot.DataDir, SafeFilename(room), bot.ID)
+	bot.mu.Unlock()
+}
+
+// SessionDir возвращает каталог текущей сессии записи бота
+func (bot *Bot) SessionDir() string {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	return bot.sessionDir
+}
+
+// updateManifest читает манифест сессии, применяет изменения и сохраняет его
+func (bot *Bot) updateManifest(update func(m *SessionManifest)) error {
+	dir := bot.SessionDir()
+	if dir == "" {
+		return nil
+	}
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	path := filepath.Join(dir, manifestFile)
+	var m SessionManifest
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &m)
+	}
+	if m.StartedAt.IsZero() {
+		m.StartedAt = time.Now()
+	}
+	m.ID, m.Room, m.BotName, m.Server = bot.ID, bot.Room, bot.BotName, bot.JitsiServer
+	update(&m)
+	m.UpdatedAt = time.Now()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Пишем через временный файл, чтобы не оставить обрезанный манифест
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeStartManifest записывает в манифест параметры текущего запуска
+func (bot *Bot) writeStartManifest(probe recordingProbe) error {
+	bot.mu.RLock()
+	segments, script := bot.segments, bot.scriptInfo
+	bot.mu.RUnlock()
+
+	return bot.updateManifest(func(m *SessionManifest) {
+		m.Segments = segments
+		m.Script = script
+		m.Recording = RecordingManifest{
+			TimesliceMs:        bot.Recording.timeslice().Milliseconds(),
+			RequestedMimeType:  bot.Recording.mimeType(),
+			MimeType:           probe.MimeType,
+			AudioBitsPerSecond: probe.AudioBitsPerSecond,
+			Channels:           bot.Recording.Channels,
+			RoomMix:            bot.Recording.RoomMix,
+			Audio:              bot.Recording.audio(),
+			Video:              bot.Recording.Video,
+			ScreenShare:        bot.Recording.ScreenShare,
+		}
+		if bot.Recording.videoEnabled() {
+			m.Recording.VideoMimeType = probe.VideoMimeType
+			m.Recording.VideoBitsPerSecond = bot.Recording.VideoBitsPerSecond
+			m.Recording.MaxWidth, m.Recording.MaxHeight = bot.Recording.maxSize()
+			m.Recording.MaxFrameRate = bot.Recording.maxFrameRate()
+		}
+	})
+}
+
+// addManifestTrack добавляет в манифест новый файл дорожки
+func (bot *Bot) addManifestTrack(p Record, file string, started time.Time) {
+	kind, source := p.Kind, p.Source
+	if kind == "" {
+		kind = "audio"
+	}
+	if source == "" {
+		source = "microphone"
+		if p.U == roomMixTrack {
+			source = "mix"
+		}
+	}
+
+	err := bot.updateManifest(func(m *SessionManifest) {
+		m.Tracks = append(m.Tracks, TrackInfo{
+			File:      file,
+			Kind:      kind,
+			Source:    source,
+			UserID:    p.UserId,
+			User:      p.User,
+			Segment:   bot.currentSegment(),
+			StartedAt: started,
+		})
+	})
+	if err != nil {
+		log.Printf("Бот %s: не удалось обновить манифест: %v", bot.ID, err)
+	}
+}
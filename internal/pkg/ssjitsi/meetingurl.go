@@ -0,0 +1,76 @@
+package ssjitsi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// meetingURL формирует адрес комнаты: {JitsiServer}/{Room}?jwt={token}#config...
+// Во фрагменте передаются переопределения config и interfaceConfig, а также
+// имя бота, как их понимает Jitsi Meet: ключ=значение в JSON.
+func (bot *Bot) meetingURL(token string) string {
+	u := strings.TrimRight(bot.JitsiServer, "/") + "/" + escapeRoomPath(bot.Room)
+	if token != "" {
+		u += "?jwt=" + url.QueryEscape(token)
+	}
+	if hash := bot.urlHashParams(); hash != "" {
+		u += "#" + hash
+	}
+	return u
+}
+
+// escapeRoomPath кодирует части имени комнаты, сохраняя "/" между ними:
+// tenant/room у многоарендных развертываний и JaaS
+func escapeRoomPath(room string) string {
+	parts := strings.Split(room, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// urlHashParams кодирует параметры фрагмента адреса в стабильном порядке
+func (bot *Bot) urlHashParams() string {
+	params := map[string]interface{}{}
+	if bot.BotName != "" {
+		params["userInfo.displayName"] = bot.BotName
+	}
+	// С подмененным микрофоном бот входит выключенным и включает его
+	// только на время объявления
+	if bot.Announcement.enabled() {
+		params["config.startWithAudioMuted"] = true
+	}
+	for k, v := range normalizeYAMLMap(bot.ConfigOverrides) {
+		params["config."+k] = v
+	}
+	for k, v := range normalizeYAMLMap(bot.InterfaceConfigOverrides) {
+		params["interfaceConfig."+k] = v
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		var value bytes.Buffer
+		enc := json.NewEncoder(&value)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(params[k]); err != nil {
+			continue
+		}
+		parts = append(parts, encodeURIComponent(k)+"="+encodeURIComponent(strings.TrimSpace(value.String())))
+	}
+	return strings.Join(parts, "&")
+}
+
+// encodeURIComponent кодирует строку так же, как одноименная функция JS,
+// которой Jitsi Meet раскодирует параметры
+func encodeURIComponent(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
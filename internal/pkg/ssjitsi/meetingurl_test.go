@@ -0,0 +1,116 @@
+package ssjitsi
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMeetingURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		bot   *Bot
+		token string
+		want  string
+	}{
+		{
+			name: "room only",
+			bot:  &Bot{JitsiServer: "https://meet.example.com/", Room: "daily"},
+			want: "https://meet.example.com/daily",
+		},
+		{
+			name: "room with spaces, slash and unicode",
+			bot:  &Bot{JitsiServer: "https://meet.example.com", Room: "Room 1/планерка"},
+			want: "https://meet.example.com/Room%201/%D0%BF%D0%BB%D0%B0%D0%BD%D0%B5%D1%80%D0%BA%D0%B0",
+		},
+		{
+			name: "tenant room",
+			bot:  &Bot{JitsiServer: "https://8x8.vc", Room: "vpaas-magic-cookie-abc/Daily Sync?"},
+			want: "https://8x8.vc/vpaas-magic-cookie-abc/Daily%20Sync%3F",
+		},
+		{
+			name:  "token is query escaped",
+			bot:   &Bot{JitsiServer: "https://meet.example.com", Room: "daily"},
+			token: "a.b+c/d=",
+			want:  "https://meet.example.com/daily?jwt=a.b%2Bc%2Fd%3D",
+		},
+		{
+			name:  "hash after token",
+			bot:   &Bot{JitsiServer: "https://meet.example.com", Room: "daily", BotName: "Recorder"},
+			token: "t",
+			want:  "https://meet.example.com/daily?jwt=t#userInfo.displayName=%22Recorder%22",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bot.meetingURL(tt.token); got != tt.want {
+				t.Errorf("meetingURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLHashParams(t *testing.T) {
+	tests := []struct {
+		name string
+		bot  *Bot
+		want string
+	}{
+		{
+			name: "empty",
+			bot:  &Bot{},
+			want: "",
+		},
+		{
+			name: "display name with reserved characters",
+			bot:  &Bot{BotName: `Bot & "Co" <1+1=2> #1`},
+			want: "userInfo.displayName=%22Bot%20%26%20%5C%22Co%5C%22%20%3C1%2B1%3D2%3E%20%231%22",
+		},
+		{
+			name: "announcement starts muted",
+			bot:  &Bot{Announcement: AnnounceOptions{Enabled: true}},
+			want: "config.startWithAudioMuted=true",
+		},
+		{
+			name: "keys are sorted across config and interfaceConfig",
+			bot: &Bot{
+				BotName:                  "Recorder",
+				ConfigOverrides:          map[string]interface{}{"prejoinConfig.enabled": false, "disableDeepLinking": true},
+				InterfaceConfigOverrides: map[string]interface{}{"SHOW_JITSI_WATERMARK": false},
+			},
+			want: "config.disableDeepLinking=true&config.prejoinConfig.enabled=false&interfaceConfig.SHOW_JITSI_WATERMARK=false&userInfo.displayName=%22Recorder%22",
+		},
+		{
+			name: "nested YAML values are encoded as JSON",
+			bot: &Bot{ConfigOverrides: map[string]interface{}{
+				"toolbarButtons": []interface{}{"microphone", "hangup"},
+				"p2p":            map[interface{}]interface{}{"enabled": false},
+			}},
+			want: "config.p2p=%7B%22enabled%22%3Afalse%7D&config.toolbarButtons=%5B%22microphone%22%2C%22hangup%22%5D",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.bot.urlHashParams()
+			if got != tt.want {
+				t.Errorf("urlHashParams() = %q, want %q", got, tt.want)
+			}
+			// Каждый параметр раскодируется обратно без потерь, как в decodeURIComponent
+			for _, part := range strings.Split(got, "&") {
+				if part == "" {
+					continue
+				}
+				if strings.Contains(part, "+") {
+					t.Errorf("parameter %q contains '+', which decodeURIComponent keeps as is", part)
+				}
+				k, v, _ := strings.Cut(part, "=")
+				if _, err := url.PathUnescape(k); err != nil {
+					t.Errorf("key %q: %v", k, err)
+				}
+				if _, err := url.PathUnescape(v); err != nil {
+					t.Errorf("value %q: %v", v, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package ssjitsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Действия модератора
+const (
+	ModLobbyState  = "lobby_state"
+	ModLobbyToggle = "lobby_toggle"
+	ModAdmit       = "admit"
+	ModReject      = "reject"
+	ModMuteAll     = "mute_all"
+	ModKick        = "kick"
+	ModSubject     = "subject"
+)
+
+var (
+	ErrNotModerator        = errors.New("bot does not have moderator rights")
+	ErrParticipantNotFound = errors.New("participant not found")
+)
+
+// LobbyState - состояние лобби конференции
+type LobbyState struct {
+	Enabled  bool           `json:"enabled"`
+	Knocking []LobbyKnocker `json:"knocking"`
+}
+
+// LobbyKnocker - участник, ожидающий допуска
+type LobbyKnocker struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// moderatorScript выполняет действие модератора через конференцию
+// lib-jitsi-meet. Участники и ожидающие в лобби ищутся по id, затем по
+// отображаемому имени.
+const moderatorScript = `(async function (action, args) {
+	const room = window.APP && APP.conference && APP.conference._room;
+	if (!room || !room.isJoined || !room.isJoined()) {
+		return { error: 'not_joined' };
+	}
+	if (!room.isModerator()) {
+		return { error: 'not_moderator' };
+	}
+	const lobby = (APP.store && APP.store.getState()['features/lobby']) || {};
+	const knocking = (lobby.knockingParticipants || []).map((p) => ({ id: p.id, name: p.name || '' }));
+	const findKnocker = (who) => knocking.find((p) => p.id === who) || knocking.find((p) => p.name === who);
+	const participants = room.getParticipants();
+	const findParticipant = (who) => participants.find((p) => p.getId() === who) ||
+		participants.find((p) => (p.getDisplayName() || '') === who);
+
+	switch (action) {
+	case 'lobby_state':
+		return { result: { enabled: !!lobby.lobbyEnabled, knocking: knocking } };
+	case 'lobby_toggle':
+		if (args.enabled) {
+			await room.enableLobby();
+		} else {
+			await room.disableLobby();
+		}
+		return { result: { enabled: !!args.enabled } };
+	case 'admit':
+	case 'reject': {
+		const targets = args.all ? knocking : [findKnocker(args.id)].filter(Boolean);
+		if (targets.length === 0 && !args.all) {
+			return { error: 'not_found' };
+		}
+		for (const p of targets) {
+			if (action === 'admit') {
+				room.lobbyApproveAccess(p.id);
+			} else {
+				room.lobbyDenyAccess(p.id);
+			}
+		}
+		return { result: { participants: targets } };
+	}
+	case 'mute_all': {
+		const muted = [];
+		for (const p of participants) {
+			if (!p.isAudioMuted || !p.isAudioMuted()) {
+				room.muteParticipant(p.getId(), 'audio');
+				muted.push({ id: p.getId(), name: p.getDisplayName() || '' });
+			}
+		}
+		return { result: { participants: muted } };
+	}
+	case 'kick': {
+		const p = findParticipant(args.id);
+		if (!p) {
+			return { error: 'not_found' };
+		}
+		room.kickParticipant(p.getId(), args.reason || '');
+		return { result: { participants: [{ id: p.getId(), name: p.getDisplayName() || '' }] } };
+	}
+	case 'subject':
+		room.setSubject(args.subject || '');
+		return { result: { subject: args.subject || '' } };
+	}
+	return { error: 'unknown action ' + action };
+})(%s, %s)`
+
+// moderatorReply - ответ moderatorScript
+type moderatorReply struct {
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// Moderate выполняет действие модератора на странице и записывает его в
+// журнал аудита. Только чтение состояния лобби в журнал не попадает.
+func (bot *Bot) Moderate(ctx context.Context, action string, args map[string]interface{}, actor, client string) (json.RawMessage, error) {
+	res, err := bot.moderate(ctx, action, args)
+	if action != ModLobbyState {
+		entry := AuditEntry{Action: action, Args: args, Actor: actor, Client: client, Result: "ok"}
+		if err != nil {
+			entry.Result, entry.Error = "error", err.Error()
+			if errors.Is(err, ErrNotModerator) {
+				entry.Result = "denied"
+			}
+		}
+		bot.audit(entry)
+	}
+	return res, err
+}
+
+func (bot *Bot) moderate(ctx context.Context, action string, args map[string]interface{}) (json.RawMessage, error) {
+	bot.mu.RLock()
+	tab := bot.Ctx
+	bot.mu.RUnlock()
+	if tab == nil {
+		return nil, ErrNotJoined
+	}
+	tab, cancel := context.WithCancel(tab)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	actionJSON, _ := json.Marshal(action)
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply moderatorReply
+	err = chromedp.Run(tab, chromedp.Evaluate(fmt.Sprintf(moderatorScript, actionJSON, argsJSON), &reply, evalAwait))
+	if err != nil {
+		return nil, fmt.Errorf("moderator action failed: %v", err)
+	}
+	switch reply.Error {
+	case "":
+		return reply.Result, nil
+	case "not_joined":
+		return nil, ErrNotJoined
+	case "not_moderator":
+		return nil, ErrNotModerator
+	case "not_found":
+		return nil, ErrParticipantNotFound
+	}
+	return nil, errors.New(reply.Error)
+}
@@ -0,0 +1,68 @@
+package ssjitsi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Participant - участник конференции, как его видит бот
+type Participant struct {
+	ID               string     `json:"id"`
+	DisplayName      string     `json:"displayName"`
+	Role             string     `json:"role"` // moderator, participant или none
+	AudioMuted       bool       `json:"audioMuted"`
+	VideoMuted       bool       `json:"videoMuted"`
+	ConnectionStatus string     `json:"connectionStatus,omitempty"` // active, inactive, interrupted, restoring
+	JoinedAt         *time.Time `json:"joinedAt,omitempty"`         // Вход или момент, когда бот впервые увидел участника
+	Recorded         bool       `json:"recorded"`                   // Пишется хотя бы одна дорожка участника
+	RecordedAudio    bool       `json:"recordedAudio"`
+	RecordedVideo    bool       `json:"recordedVideo"`
+}
+
+// pageParticipant - участник в ответе ssbot.participants()
+type pageParticipant struct {
+	Participant
+	JoinedAtMs int64 `json:"joinedAt"`
+}
+
+// Participants возвращает текущих участников конференции
+func (bot *Bot) Participants(ctx context.Context) ([]Participant, error) {
+	bot.mu.RLock()
+	tab := bot.Ctx
+	bot.mu.RUnlock()
+	if tab == nil {
+		return nil, ErrNotJoined
+	}
+	if !bot.HasCapability("participants") {
+		return nil, errors.New("recorder script does not support the participant list")
+	}
+	tab, cancel := context.WithCancel(tab)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	var list []pageParticipant
+	if err := chromedp.Run(tab, chromedp.Evaluate(`ssbot.participants()`, &list)); err != nil {
+		return nil, fmt.Errorf("failed to list participants: %v", err)
+	}
+	return pageParticipants(list), nil
+}
+
+// pageParticipants переводит время входа из миллисекунд страницы во время
+// и отмечает участников, которые пишутся
+func pageParticipants(list []pageParticipant) []Participant {
+	res := make([]Participant, 0, len(list))
+	for _, p := range list {
+		if p.JoinedAtMs > 0 {
+			t := time.UnixMilli(p.JoinedAtMs)
+			p.Participant.JoinedAt = &t
+		}
+		p.Recorded = p.RecordedAudio || p.RecordedVideo
+		res = append(res, p.Participant)
+	}
+	return res
+}
@@ -0,0 +1,58 @@
+package ssjitsi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPageParticipants(t *testing.T) {
+	joined := time.UnixMilli(1700000000123)
+
+	tests := []struct {
+		name string
+		page string // Ответ ssbot.participants() для одного участника
+		want Participant
+	}{
+		{
+			name: "joined and recorded",
+			page: `{"id":"a1","displayName":"Alice","role":"moderator","joinedAt":1700000000123,"recordedAudio":true}`,
+			want: Participant{ID: "a1", DisplayName: "Alice", Role: "moderator", JoinedAt: &joined, Recorded: true, RecordedAudio: true},
+		},
+		{
+			name: "video only",
+			page: `{"id":"b2","displayName":"Bob","role":"participant","audioMuted":true,"joinedAt":1700000000123,"recordedVideo":true}`,
+			want: Participant{ID: "b2", DisplayName: "Bob", Role: "participant", AudioMuted: true, JoinedAt: &joined, Recorded: true, RecordedVideo: true},
+		},
+		{
+			// Время входа неизвестно - поле не выводится
+			name: "unknown join time",
+			page: `{"id":"c3","displayName":"Carol","role":"none","joinedAt":0}`,
+			want: Participant{ID: "c3", DisplayName: "Carol", Role: "none"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p pageParticipant
+			if err := json.Unmarshal([]byte(tt.page), &p); err != nil {
+				t.Fatal(err)
+			}
+			res := pageParticipants([]pageParticipant{p})
+			if len(res) != 1 {
+				t.Fatalf("got %d participants, want 1", len(res))
+			}
+			got := res[0]
+			if (got.JoinedAt == nil) != (tt.want.JoinedAt == nil) || got.JoinedAt != nil && !got.JoinedAt.Equal(*tt.want.JoinedAt) {
+				t.Errorf("JoinedAt = %v, want %v", got.JoinedAt, tt.want.JoinedAt)
+			}
+			got.JoinedAt, tt.want.JoinedAt = nil, nil
+			if got != tt.want {
+				t.Errorf("participant = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if res := pageParticipants(nil); res == nil || len(res) != 0 {
+		t.Errorf("pageParticipants(nil) = %#v, want an empty list", res)
+	}
+}
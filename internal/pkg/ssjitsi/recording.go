@@ -0,0 +1,221 @@
+package ssjitsi
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Имя общей дорожки комнаты
+const roomMixTrack = "room_mix"
+
+// Параметры записи по умолчанию
+const (
+	defaultTimeslice = 10 * time.Second
+	defaultMimeType  = "audio/webm"
+	defaultCodec     = "opus"
+
+	defaultVideoCodec   = "vp8"
+	defaultMaxWidth     = 1280
+	defaultMaxHeight    = 720
+	defaultMaxFrameRate = 15
+)
+
+// RecordingOptions задает параметры MediaRecorder для дорожек участников
+type RecordingOptions struct {
+	Timeslice          time.Duration `yaml:"Timeslice"`          // Длительность чанка (по умолчанию 10s)
+	MimeType           string        `yaml:"MimeType"`           // Контейнер: audio/webm (по умолчанию), audio/ogg, audio/mp4
+	Codec              string        `yaml:"Codec"`              // Кодек: opus (по умолчанию), пустая строка - на выбор браузера
+	AudioBitsPerSecond int           `yaml:"AudioBitsPerSecond"` // Битрейт; 0 - по умолчанию браузера
+	Channels           int           `yaml:"Channels"`           // 1 - моно, 2 - стерео, 0 - как у источника
+	RoomMix            bool          `yaml:"RoomMix"`            // Дополнительно писать общую дорожку комнаты room_mix
+
+	Audio              *bool  `yaml:"Audio"`              // Писать аудио участников (по умолчанию true)
+	Video              bool   `yaml:"Video"`              // Писать камеры участников
+	ScreenShare        bool   `yaml:"ScreenShare"`        // Писать демонстрацию экрана
+	VideoCodec         string `yaml:"VideoCodec"`         // vp8 (по умолчанию) или vp9
+	VideoBitsPerSecond int    `yaml:"VideoBitsPerSecond"` // Битрейт видео; 0 - по умолчанию браузера
+	MaxWidth           int    `yaml:"MaxWidth"`           // Ограничение ширины кадра (по умолчанию 1280)
+	MaxHeight          int    `yaml:"MaxHeight"`          // Ограничение высоты кадра (по умолчанию 720)
+	MaxFrameRate       int    `yaml:"MaxFrameRate"`       // Ограничение частоты кадров (по умолчанию 15)
+}
+
+// recordingConfig - параметры записи, передаваемые скрипту
+type recordingConfig struct {
+	Timeslice          int64  `json:"timeslice"`
+	MimeType           string `json:"mimeType"`
+	AudioBitsPerSecond int    `json:"audioBitsPerSecond,omitempty"`
+	Channels           int    `json:"channels,omitempty"`
+	RoomMix            bool   `json:"roomMix,omitempty"`
+
+	Audio              bool   `json:"audio"`
+	Video              bool   `json:"video,omitempty"`
+	ScreenShare        bool   `json:"screenShare,omitempty"`
+	VideoMimeType      string `json:"videoMimeType,omitempty"`
+	VideoBitsPerSecond int    `json:"videoBitsPerSecond,omitempty"`
+	MaxWidth           int    `json:"maxWidth,omitempty"`
+	MaxHeight          int    `json:"maxHeight,omitempty"`
+	MaxFrameRate       int    `json:"maxFrameRate,omitempty"`
+}
+
+// recordingProbe - что браузер сообщил о параметрах записи
+type recordingProbe struct {
+	Supported          bool   `json:"supported"`
+	MimeType           string `json:"mimeType"`
+	AudioBitsPerSecond int    `json:"audioBitsPerSecond"`
+	VideoSupported     bool   `json:"videoSupported"`
+	VideoMimeType      string `json:"videoMimeType"`
+}
+
+// timeslice возвращает длительность чанка с учетом значения по умолчанию
+func (o RecordingOptions) timeslice() time.Duration {
+	if o.Timeslice > 0 {
+		return o.Timeslice
+	}
+	return defaultTimeslice
+}
+
+// mimeType возвращает полный MIME тип с кодеком, например audio/webm;codecs=opus
+func (o RecordingOptions) mimeType() string {
+	mime := o.MimeType
+	if mime == "" {
+		mime = defaultMimeType
+	}
+	codec := o.Codec
+	if codec == "" && o.MimeType == "" {
+		codec = defaultCodec
+	}
+	if codec != "" && !strings.Contains(mime, "codecs=") {
+		mime += ";codecs=" + codec
+	}
+	return mime
+}
+
+// validate проверяет параметры записи при загрузке конфигурации
+func (o RecordingOptions) validate() error {
+	if o.Channels < 0 || o.Channels > 2 {
+		return fmt.Errorf("Recording.Channels must be 0, 1 or 2, got %d", o.Channels)
+	}
+	switch o.VideoCodec {
+	case "", "vp8", "vp9":
+	default:
+		return fmt.Errorf("Recording.VideoCodec must be vp8 or vp9, got %q", o.VideoCodec)
+	}
+	if o.Timeslice > 0 && o.Timeslice < 100*time.Millisecond {
+		return fmt.Errorf("Recording.Timeslice %s is too small", o.Timeslice)
+	}
+	return nil
+}
+
+// pageConfig возвращает параметры записи для скрипта
+func (o RecordingOptions) pageConfig() recordingConfig {
+	cfg := recordingConfig{
+		Timeslice:          o.timeslice().Milliseconds(),
+		MimeType:           o.mimeType(),
+		AudioBitsPerSecond: o.AudioBitsPerSecond,
+		Channels:           o.Channels,
+		RoomMix:            o.RoomMix,
+	}
+	cfg.Audio = o.audio()
+	if o.videoEnabled() {
+		cfg.Video, cfg.ScreenShare = o.Video, o.ScreenShare
+		cfg.VideoMimeType = o.videoMimeType()
+		cfg.VideoBitsPerSecond = o.VideoBitsPerSecond
+		cfg.MaxWidth, cfg.MaxHeight = o.maxSize()
+		cfg.MaxFrameRate = o.maxFrameRate()
+	}
+	return cfg
+}
+
+// audio сообщает, писать ли аудио участников
+func (o RecordingOptions) audio() bool {
+	return o.Audio == nil || *o.Audio
+}
+
+// videoEnabled сообщает, включена ли запись видео или демонстрации экрана
+func (o RecordingOptions) videoEnabled() bool {
+	return o.Video || o.ScreenShare
+}
+
+// videoMimeType возвращает MIME тип видеодорожек, например video/webm;codecs=vp8
+func (o RecordingOptions) videoMimeType() string {
+	codec := o.VideoCodec
+	if codec == "" {
+		codec = defaultVideoCodec
+	}
+	return "video/webm;codecs=" + codec
+}
+
+// maxSize возвращает ограничение размера кадра
+func (o RecordingOptions) maxSize() (int, int) {
+	w, h := o.MaxWidth, o.MaxHeight
+	if w <= 0 {
+		w = defaultMaxWidth
+	}
+	if h <= 0 {
+		h = defaultMaxHeight
+	}
+	return w, h
+}
+
+// maxFrameRate возвращает ограничение частоты кадров
+func (o RecordingOptions) maxFrameRate() int {
+	if o.MaxFrameRate > 0 {
+		return o.MaxFrameRate
+	}
+	return defaultMaxFrameRate
+}
+
+// fileExtension подбирает расширение файла дорожки по MIME типу
+func fileExtension(mime string) string {
+	base, _, _ := strings.Cut(mime, ";")
+	switch strings.TrimSpace(base) {
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/mp4":
+		return ".m4a"
+	case "video/mp4":
+		return ".mp4"
+	case "audio/wav", "audio/wave":
+		return ".wav"
+	}
+	return ".webm"
+}
+
+// checkRecording спрашивает у браузера, поддерживаются ли параметры записи,
+// и какие значения MediaRecorder применит на самом деле
+func (bot *Bot) checkRecording() (recordingProbe, error) {
+	var probe recordingProbe
+	err := chromedp.Run(bot.Ctx, chromedp.Evaluate(`ssbot.recordingSettings()`, &probe))
+	if err != nil {
+		return probe, fmt.Errorf("failed to check recording settings: %v", err)
+	}
+	mime := bot.Recording.mimeType()
+	if !probe.Supported {
+		return probe, fmt.Errorf("recording type %q is not supported by the browser", mime)
+	}
+
+	if bot.Recording.videoEnabled() && !probe.VideoSupported {
+		return probe, fmt.Errorf("video recording type %q is not supported by the browser", bot.Recording.videoMimeType())
+	}
+
+	bot.mu.Lock()
+	bot.trackExt = fileExtension(probe.MimeType)
+	bot.mu.Unlock()
+
+	log.Printf("Бот %s: запись %s, битрейт %d, чанк %s", bot.ID, probe.MimeType, probe.AudioBitsPerSecond, bot.Recording.timeslice())
+	return probe, nil
+}
+
+// trackExtension возвращает расширение файлов дорожек текущего запуска
+func (bot *Bot) trackExtension() string {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	if bot.trackExt == "" {
+		return fileExtension(bot.Recording.mimeType())
+	}
+	return bot.trackExt
+}
@@ -0,0 +1,147 @@
+package ssjitsi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordingOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options RecordingOptions
+		wantErr string
+	}{
+		{"defaults", RecordingOptions{}, ""},
+		{"stereo", RecordingOptions{Channels: 2, Timeslice: 100 * time.Millisecond}, ""},
+		{"negative channels", RecordingOptions{Channels: -1}, "Recording.Channels must be 0, 1 or 2, got -1"},
+		{"too many channels", RecordingOptions{Channels: 6}, "Recording.Channels must be 0, 1 or 2, got 6"},
+		{"timeslice too small", RecordingOptions{Timeslice: 50 * time.Millisecond}, "Recording.Timeslice 50ms is too small"},
+		{"video vp9", RecordingOptions{Video: true, VideoCodec: "vp9"}, ""},
+		{"unknown video codec", RecordingOptions{VideoCodec: "h264"}, `Recording.VideoCodec must be vp8 or vp9, got "h264"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecordingMimeType(t *testing.T) {
+	tests := []struct {
+		options RecordingOptions
+		mime    string
+		ext     string
+	}{
+		{RecordingOptions{}, "audio/webm;codecs=opus", ".webm"},
+		{RecordingOptions{Codec: "vorbis"}, "audio/webm;codecs=vorbis", ".webm"},
+		{RecordingOptions{MimeType: "audio/ogg"}, "audio/ogg", ".ogg"},
+		{RecordingOptions{MimeType: "audio/ogg", Codec: "opus"}, "audio/ogg;codecs=opus", ".ogg"},
+		{RecordingOptions{MimeType: "audio/mp4;codecs=mp4a.40.2", Codec: "opus"}, "audio/mp4;codecs=mp4a.40.2", ".m4a"},
+		{RecordingOptions{MimeType: "audio/wav"}, "audio/wav", ".wav"},
+	}
+	for _, tt := range tests {
+		if got := tt.options.mimeType(); got != tt.mime {
+			t.Errorf("%+v: mimeType() = %q, want %q", tt.options, got, tt.mime)
+		}
+		if got := (&Bot{Recording: tt.options}).trackExtension(); got != tt.ext {
+			t.Errorf("%+v: trackExtension() = %q, want %q", tt.options, got, tt.ext)
+		}
+	}
+}
+
+func TestConfigScript(t *testing.T) {
+	tests := []struct {
+		name string
+		bot  *Bot
+		want recordingConfig
+	}{
+		{
+			name: "defaults",
+			bot:  &Bot{},
+			want: recordingConfig{Timeslice: 10000, MimeType: "audio/webm;codecs=opus", Audio: true},
+		},
+		{
+			name: "custom audio",
+			bot: &Bot{Recording: RecordingOptions{
+				Timeslice:          2 * time.Second,
+				MimeType:           "audio/ogg",
+				AudioBitsPerSecond: 64000,
+				Channels:           1,
+				RoomMix:            true,
+			}},
+			want: recordingConfig{Timeslice: 2000, MimeType: "audio/ogg", AudioBitsPerSecond: 64000, Channels: 1, RoomMix: true, Audio: true},
+		},
+		{
+			// Параметры видео без Video и ScreenShare не передаются
+			name: "video options without video",
+			bot:  &Bot{Recording: RecordingOptions{VideoCodec: "vp9", MaxWidth: 640}},
+			want: recordingConfig{Timeslice: 10000, MimeType: "audio/webm;codecs=opus", Audio: true},
+		},
+		{
+			name: "video defaults",
+			bot:  &Bot{Recording: RecordingOptions{Video: true}},
+			want: recordingConfig{
+				Timeslice: 10000, MimeType: "audio/webm;codecs=opus", Audio: true,
+				Video: true, VideoMimeType: "video/webm;codecs=vp8", MaxWidth: 1280, MaxHeight: 720, MaxFrameRate: 15,
+			},
+		},
+		{
+			name: "screen share only",
+			bot: &Bot{Recording: RecordingOptions{
+				Audio:              new(bool),
+				ScreenShare:        true,
+				VideoCodec:         "vp9",
+				VideoBitsPerSecond: 1500000,
+				MaxWidth:           1920,
+				MaxFrameRate:       5,
+			}},
+			want: recordingConfig{
+				Timeslice: 10000, MimeType: "audio/webm;codecs=opus",
+				ScreenShare: true, VideoMimeType: "video/webm;codecs=vp9", VideoBitsPerSecond: 1500000,
+				MaxWidth: 1920, MaxHeight: 720, MaxFrameRate: 5,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := tt.bot.configScript()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if script.Name != "config" || !script.Required {
+				t.Errorf("script = %+v", script)
+			}
+			data, ok := strings.CutPrefix(script.Source, "window.ssbot_config = ")
+			if !ok || !strings.HasSuffix(data, ";") {
+				t.Fatalf("unexpected source %q", script.Source)
+			}
+
+			var cfg struct {
+				Recording   recordingConfig `json:"recording"`
+				UploadURL   *string         `json:"uploadUrl"`
+				UploadToken *string         `json:"uploadToken"`
+			}
+			if err := json.Unmarshal([]byte(strings.TrimSuffix(data, ";")), &cfg); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Recording != tt.want {
+				t.Errorf("recording = %+v, want %+v", cfg.Recording, tt.want)
+			}
+			// Без приемника чанков скрипт отправляет их через привязку CDP
+			if cfg.UploadURL != nil || cfg.UploadToken != nil {
+				t.Errorf("upload config without uploader: %s", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package ssjitsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// Сколько ждать, не вошла ли перезагруженная страница в конференцию сама
+const reloadSelfJoinTimeout = 15 * time.Second
+
+// Как часто зарегистрированный скрипт проверяет, вошла ли страница в конференцию
+const pageScriptJoinPoll = 250 * time.Millisecond
+
+// wrapPageScript оборачивает скрипт так, чтобы он выполнялся один раз на
+// документ и только после входа в конференцию: скрипт записи подписывается
+// на события APP.conference._room, которой до входа нет. На новом документе
+// после перезагрузки обертка ждет входа сама; при внедрении в уже вошедшую
+// страницу скрипт выполняется сразу.
+func wrapPageScript(s pageScript) string {
+	name, _ := json.Marshal(s.Name)
+	return fmt.Sprintf(`(function () {
+	const name = %s;
+	if (window.top !== window) {
+		return;
+	}
+	window.ssbot_loaded = window.ssbot_loaded || {};
+	if (window.ssbot_loaded[name]) {
+		return;
+	}
+	const run = function () {
+		if (window.ssbot_loaded[name]) {
+			return;
+		}
+		window.ssbot_loaded[name] = true;
+%s
+	};
+	const start = function () {
+		const conf = window.APP && APP.conference;
+		if (conf && conf.isJoined && conf.isJoined()) {
+			run();
+		} else if (!window.ssbot_loaded[name]) {
+			setTimeout(start, %d);
+		}
+	};
+	start();
+})()`, name, s.Source, pageScriptJoinPoll.Milliseconds())
+}
+
+// registerScripts регистрирует скрипты для каждого нового документа вкладки,
+// чтобы запись продолжалась после перезагрузки страницы
+func (bot *Bot) registerScripts(ctx context.Context, scripts []pageScript) error {
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		for _, s := range scripts {
+			if _, err := page.AddScriptToEvaluateOnNewDocument(wrapPageScript(s)).Do(ctx); err != nil {
+				return fmt.Errorf("failed to register script %s: %v", s.Name, err)
+			}
+		}
+		return nil
+	}))
+}
+
+// nextSegment начинает новый сегмент записи. Первый сегмент имеет номер 0,
+// следующие (после перезагрузки или перезапуска) пишутся в отдельные файлы.
+func (bot *Bot) nextSegment() int {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	bot.segment = bot.segments
+	bot.segments++
+	return bot.segment
+}
+
+// currentSegment возвращает номер текущего сегмента записи
+func (bot *Bot) currentSegment() int {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	return bot.segment
+}
+
+// onNavigated вызывается при загрузке нового документа в главном фрейме
+func (bot *Bot) onNavigated() {
+	bot.mu.Lock()
+	if bot.Status != StatusRunning || bot.rejoining {
+		bot.mu.Unlock()
+		return
+	}
+	bot.rejoining = true
+	bot.mu.Unlock()
+
+	go bot.rejoin()
+}
+
+// rejoin восстанавливает работу после перезагрузки страницы: при
+// необходимости заново входит в конференцию и продолжает ту же сессию
+// с новым сегментом записи
+func (bot *Bot) rejoin() {
+	defer func() {
+		bot.mu.Lock()
+		bot.rejoining = false
+		bot.mu.Unlock()
+	}()
+
+	bot.mu.RLock()
+	ctx := bot.Ctx
+	bot.mu.RUnlock()
+	if ctx == nil {
+		return
+	}
+
+	segment := bot.nextSegment()
+	log.Printf("Бот %s: страница перезагружена, восстанавливаем запись (сегмент %d)", bot.ID, segment)
+	bot.Events.Add("info", fmt.Sprintf("page reloaded, continuing session with segment %d", segment))
+	bot.SetStatus(StatusStarting)
+
+	if err := bot.reenter(ctx); err != nil {
+		bot.fail(fmt.Errorf("rejoin failed: %v", err))
+		return
+	}
+
+	bot.updateManifest(func(m *SessionManifest) {
+		m.Segments = segment + 1
+	})
+
+	bot.SetStatus(StatusRunning)
+	log.Printf("Бот %s: запись продолжена после перезагрузки", bot.ID)
+}
+
+// reenter проверяет, вошла ли страница в конференцию, и если нет - заново
+// выполняет сценарий входа
+func (bot *Bot) reenter(ctx context.Context) error {
+	if !bot.selfJoined(ctx) {
+		bot.Events.Add("info", "rejoin required")
+
+		var token string
+		if method := getAuthMethod(bot); method == "JWT" || method == "TokenService" {
+			var err error
+			token, _, err = bot.meetingToken()
+			if err != nil {
+				return fmt.Errorf("failed to get meeting token: %v", err)
+			}
+		}
+		if err := bot.runJoinFlow(ctx, token); err != nil {
+			return err
+		}
+	}
+
+	if err := bot.waitJoined(ctx); err != nil {
+		return err
+	}
+	if bot.E2EEPassphrase != "" {
+		if err := bot.enableE2EE(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := chromedp.Run(ctx, runtime.AddBinding("ssbot_writeSound")); err != nil {
+		return err
+	}
+	// Скрипты уже выполнены на новом документе; повторный вызов только
+	// проверяет их версию
+	return bot.injectScripts(bot.scripts)
+}
+
+// selfJoined ждет, не войдет ли перезагруженная страница в конференцию сама;
+// лобби и запрос пароля тоже означают, что сценарий входа повторять не нужно
+func (bot *Bot) selfJoined(ctx context.Context) bool {
+	deadline := time.Now().Add(reloadSelfJoinTimeout)
+	for time.Now().Before(deadline) {
+		var state string
+		if err := chromedp.Run(ctx, chromedp.Evaluate(joinStateScript, &state)); err == nil {
+			switch state {
+			case "joined":
+				return true
+			case "lobby", "knocking", "password", "rejected":
+				// Страница сама ждет ввода - дальше разберется waitJoined
+				return true
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(time.Second):
+		}
+	}
+	return false
+}
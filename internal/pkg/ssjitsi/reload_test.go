@@ -0,0 +1,59 @@
+package ssjitsi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrackSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments int // Сколько раз начинался сегмент: запуск и перезагрузки
+		record   Record
+		want     string
+	}{
+		{"first segment", 1, Record{U: "a1", UserId: "u1", User: "Alice", Room: "daily"}, "u1_a1"},
+		{"after reload", 2, Record{U: "a1", UserId: "u1", User: "Alice", Room: "daily"}, "u1_a1_s1"},
+		{"after two reloads", 3, Record{U: "a1", UserId: "u1", User: "Alice", Room: "daily"}, "u1_a1_s2"},
+		{"room mix after reload", 2, Record{U: roomMixTrack, UserId: "bot", Room: "daily"}, "room_mix_s1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{ID: "bot", DataDir: t.TempDir()}
+			for i := 0; i < tt.segments; i++ {
+				bot.nextSegment()
+			}
+			if got := bot.currentSegment(); got != tt.segments-1 {
+				t.Fatalf("currentSegment() = %d, want %d", got, tt.segments-1)
+			}
+			if err := bot.writeTrackChunk(tt.record, strings.NewReader("chunk")); err != nil {
+				t.Fatal(err)
+			}
+
+			dir := filepath.Join(bot.DataDir, "daily", "bot")
+			for _, name := range []string{tt.want + ".webm", tt.want + ".json"} {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("segment file: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapPageScript(t *testing.T) {
+	wrapped := wrapPageScript(pageScript{Name: `my "plugin"`, Source: "window.x = 100%;"})
+	for _, want := range []string{
+		`const name = "my \"plugin\"";`,
+		"window.x = 100%;",
+		// Скрипт ждет входа в конференцию и выполняется один раз на документ
+		"conf.isJoined()",
+		"window.ssbot_loaded[name] = true;",
+		"setTimeout(start, 250)",
+	} {
+		if !strings.Contains(wrapped, want) {
+			t.Errorf("wrapped script does not contain %q:\n%s", want, wrapped)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package ssjitsi
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// Имя дорожки записи экрана бота
+const screencastTrack = "screen"
+
+// Параметры записи экрана по умолчанию
+const (
+	defaultScreencastWidth     = 1280
+	defaultScreencastHeight    = 720
+	defaultScreencastFrameRate = 10
+	defaultScreencastQuality   = 80
+	defaultScreencastBitrate   = 1000000
+	defaultFFmpeg              = "ffmpeg"
+)
+
+// ScreencastOptions включает запись всей страницы встречи, как ее видит бот.
+// Кадры Page.startScreencast кодируются ffmpeg в screen.webm.
+type ScreencastOptions struct {
+	Enabled            bool   `yaml:"Enabled"`            // Писать экран бота
+	Width              int    `yaml:"Width"`              // Ширина области просмотра (по умолчанию 1280)
+	Height             int    `yaml:"Height"`             // Высота области просмотра (по умолчанию 720)
+	FrameRate          int    `yaml:"FrameRate"`          // Частота кадров видео (по умолчанию 10)
+	Quality            int    `yaml:"Quality"`            // Качество JPEG кадров 1-100 (по умолчанию 80)
+	VideoBitsPerSecond int    `yaml:"VideoBitsPerSecond"` // Битрейт VP8 (по умолчанию 1000000)
+	FFmpeg             string `yaml:"FFmpeg"`             // Путь к ffmpeg (по умолчанию из PATH)
+}
+
+// ScreencastManifest - параметры записи экрана в манифесте сессии
+type ScreencastManifest struct {
+	Width              int `json:"width"`
+	Height             int `json:"height"`
+	FrameRate          int `json:"frameRate"`
+	Quality            int `json:"quality"`
+	VideoBitsPerSecond int `json:"videoBitsPerSecond"`
+}
+
+// validate проверяет параметры записи экрана при загрузке конфигурации
+func (o ScreencastOptions) validate() error {
+	if o.Width < 0 || o.Height < 0 || o.Width%2 != 0 || o.Height%2 != 0 {
+		return fmt.Errorf("Screencast viewport must have positive even dimensions, got %dx%d", o.Width, o.Height)
+	}
+	if o.FrameRate < 0 || o.FrameRate > 60 {
+		return fmt.Errorf("Screencast.FrameRate must be between 1 and 60, got %d", o.FrameRate)
+	}
+	if o.Quality < 0 || o.Quality > 100 {
+		return fmt.Errorf("Screencast.Quality must be between 1 and 100, got %d", o.Quality)
+	}
+	return nil
+}
+
+// settings возвращает параметры с учетом значений по умолчанию
+func (o ScreencastOptions) settings() ScreencastManifest {
+	s := ScreencastManifest{
+		Width:              o.Width,
+		Height:             o.Height,
+		FrameRate:          o.FrameRate,
+		Quality:            o.Quality,
+		VideoBitsPerSecond: o.VideoBitsPerSecond,
+	}
+	if s.Width == 0 || s.Height == 0 {
+		s.Width, s.Height = defaultScreencastWidth, defaultScreencastHeight
+	}
+	if s.FrameRate == 0 {
+		s.FrameRate = defaultScreencastFrameRate
+	}
+	if s.Quality == 0 {
+		s.Quality = defaultScreencastQuality
+	}
+	if s.VideoBitsPerSecond == 0 {
+		s.VideoBitsPerSecond = defaultScreencastBitrate
+	}
+	return s
+}
+
+// screencast кодирует кадры вкладки в видео с постоянной частотой кадров.
+// Chrome присылает кадр только при изменении страницы, поэтому последний
+// кадр повторяется - длительность видео совпадает с реальным временем, и
+// его можно совместить с аудиодорожками по времени начала.
+type screencast struct {
+	settings ScreencastManifest
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	file     string
+
+	mu    sync.Mutex
+	frame []byte
+}
+
+// startScreencast задает область просмотра, запускает ffmpeg и трансляцию
+// кадров вкладки. Запись завершается вместе с контекстом вкладки.
+func (bot *Bot) startScreencast(ctx context.Context) error {
+	if !bot.Screencast.Enabled {
+		return nil
+	}
+	dir := bot.SessionDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	s := &screencast{settings: bot.Screencast.settings()}
+	track := screencastTrack
+	if segment := bot.currentSegment(); segment > 0 {
+		track += "_s" + strconv.Itoa(segment)
+	}
+	s.file = filepath.Join(dir, track+".webm")
+
+	ffmpeg := bot.Screencast.FFmpeg
+	if ffmpeg == "" {
+		ffmpeg = defaultFFmpeg
+	}
+	w, h := strconv.Itoa(s.settings.Width), strconv.Itoa(s.settings.Height)
+	// Процесс не привязан к контексту вкладки: после ее закрытия ffmpeg
+	// должен дописать файл, а не быть убитым
+	s.cmd = exec.Command(ffmpeg,
+		"-hide_banner", "-loglevel", "error",
+		"-f", "image2pipe", "-framerate", strconv.Itoa(s.settings.FrameRate), "-c:v", "mjpeg", "-i", "-",
+		"-vf", "scale="+w+":"+h+":force_original_aspect_ratio=decrease,pad="+w+":"+h+":(ow-iw)/2:(oh-ih)/2,format=yuv420p",
+		"-c:v", "libvpx", "-deadline", "realtime", "-cpu-used", "8",
+		"-b:v", strconv.Itoa(s.settings.VideoBitsPerSecond),
+		"-y", s.file,
+	)
+	s.cmd.Stderr = os.Stderr
+	stdin, err := s.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	s.stdin = stdin
+	if err := s.cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg for screencast: %v", err)
+	}
+
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		if ev, ok := ev.(*page.EventScreencastFrame); ok {
+			s.onFrame(ev.Data)
+			// Без подтверждения Chrome перестает присылать кадры
+			go chromedp.Run(ctx, page.ScreencastFrameAck(ev.SessionID))
+		}
+	})
+
+	err = chromedp.Run(ctx,
+		emulation.SetDeviceMetricsOverride(int64(s.settings.Width), int64(s.settings.Height), 1, false),
+		page.StartScreencast().
+			WithFormat(page.ScreencastFormatJpeg).
+			WithQuality(int64(s.settings.Quality)).
+			WithMaxWidth(int64(s.settings.Width)).
+			WithMaxHeight(int64(s.settings.Height)),
+	)
+	if err != nil {
+		stdin.Close()
+		s.cmd.Wait()
+		return fmt.Errorf("failed to start screencast: %v", err)
+	}
+
+	go bot.runScreencast(ctx, s, track)
+
+	bot.updateManifest(func(m *SessionManifest) {
+		settings := s.settings
+		m.Screencast = &settings
+	})
+	log.Printf("Бот %s: запись экрана %sx%s, %d кадров/с в %s", bot.ID, w, h, s.settings.FrameRate, s.file)
+	bot.Events.Add("info", fmt.Sprintf("screencast started: %s", filepath.Base(s.file)))
+	return nil
+}
+
+// onFrame запоминает последний кадр трансляции
+func (s *screencast) onFrame(data string) {
+	frame, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.frame = frame
+	s.mu.Unlock()
+}
+
+// runScreencast передает кадры в ffmpeg с постоянной частотой, пока открыта
+// вкладка. Если ffmpeg не успевает, недостающие кадры дописываются
+// повторами, чтобы видео не отставало от реального времени.
+func (bot *Bot) runScreencast(ctx context.Context, s *screencast, track string) {
+	interval := time.Second / time.Duration(s.settings.FrameRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var started time.Time
+	var written int64
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case now := <-ticker.C:
+			s.mu.Lock()
+			frame := s.frame
+			s.mu.Unlock()
+			if frame == nil {
+				continue
+			}
+			if started.IsZero() {
+				// Время первого кадра - начало видео, как у аудиодорожек
+				started = now
+				wrf(filepath.Join(filepath.Dir(s.file), track+".json"), []byte(strconv.Itoa(int(started.UnixMilli()))))
+				bot.addManifestTrack(Record{Kind: "video", Source: screencastTrack}, filepath.Base(s.file), started)
+			}
+			due := int64(now.Sub(started)/interval) + 1
+			for ; written < due; written++ {
+				if _, err := s.stdin.Write(frame); err != nil {
+					log.Printf("Бот %s: ffmpeg перестал принимать кадры: %v", bot.ID, err)
+					bot.Events.Add("error", fmt.Sprintf("screencast encoder failed: %v", err))
+					break loop
+				}
+			}
+		}
+	}
+
+	s.stdin.Close()
+	if err := s.cmd.Wait(); err != nil {
+		log.Printf("Бот %s: ffmpeg завершился с ошибкой: %v", bot.ID, err)
+		return
+	}
+	log.Printf("Бот %s: запись экрана сохранена в %s (%d кадров)", bot.ID, s.file, written)
+}
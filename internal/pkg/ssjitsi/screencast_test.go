@@ -0,0 +1,66 @@
+package ssjitsi
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestScreencastOptions(t *testing.T) {
+	defaults := ScreencastManifest{Width: 1280, Height: 720, FrameRate: 10, Quality: 80, VideoBitsPerSecond: 1000000}
+
+	tests := []struct {
+		name    string
+		options ScreencastOptions
+		want    ScreencastManifest
+		wantErr string
+	}{
+		{name: "defaults", want: defaults},
+		{
+			name:    "custom",
+			options: ScreencastOptions{Width: 1920, Height: 1080, FrameRate: 25, Quality: 60, VideoBitsPerSecond: 2500000},
+			want:    ScreencastManifest{Width: 1920, Height: 1080, FrameRate: 25, Quality: 60, VideoBitsPerSecond: 2500000},
+		},
+		{
+			// Размер задается только парой, иначе берется размер по умолчанию
+			name:    "width only",
+			options: ScreencastOptions{Width: 1920, FrameRate: 5},
+			want:    ScreencastManifest{Width: 1280, Height: 720, FrameRate: 5, Quality: 80, VideoBitsPerSecond: 1000000},
+		},
+		{name: "odd width", options: ScreencastOptions{Width: 1281, Height: 720}, wantErr: "Screencast viewport must have positive even dimensions, got 1281x720"},
+		{name: "negative height", options: ScreencastOptions{Width: 1280, Height: -720}, wantErr: "Screencast viewport must have positive even dimensions, got 1280x-720"},
+		{name: "frame rate too high", options: ScreencastOptions{FrameRate: 61}, wantErr: "Screencast.FrameRate must be between 1 and 60, got 61"},
+		{name: "negative quality", options: ScreencastOptions{Quality: -1}, wantErr: "Screencast.Quality must be between 1 and 100, got -1"},
+		{name: "quality too high", options: ScreencastOptions{Quality: 101}, wantErr: "Screencast.Quality must be between 1 and 100, got 101"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.validate()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("validate() = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.options.settings(); got != tt.want {
+				t.Errorf("settings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreencastFrame(t *testing.T) {
+	s := &screencast{}
+	s.onFrame(base64.StdEncoding.EncodeToString([]byte("frame 1")))
+	// Битый кадр пропускается, повторяется последний целый
+	s.onFrame("not base64!")
+	if string(s.frame) != "frame 1" {
+		t.Errorf("frame = %q, want %q", s.frame, "frame 1")
+	}
+	s.onFrame(base64.StdEncoding.EncodeToString([]byte("frame 2")))
+	if string(s.frame) != "frame 2" {
+		t.Errorf("frame = %q, want %q", s.frame, "frame 2")
+	}
+}
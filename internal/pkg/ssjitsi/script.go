@@ -0,0 +1,128 @@
+package ssjitsi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Версия протокола встроенного скрипта, с которой работает Go сторона
+const scriptProtocolVersion = 1
+
+//go:embed script.js
+var defaultRecorderScript string
+
+// ScriptInfo - ответ внедренного скрипта на проверку версии
+type ScriptInfo struct {
+	Version      int      `json:"version"`
+	Capabilities []string `json:"capabilities"`
+	Plugins      []string `json:"plugins"`
+}
+
+// pageScript - скрипт, внедряемый на страницу встречи
+type pageScript struct {
+	Name     string
+	Source   string
+	Required bool // Ошибка выполнения прерывает запуск бота
+}
+
+// loadScripts возвращает скрипт записи (встроенный или из Script) и плагины бота
+func (bot *Bot) loadScripts() ([]pageScript, error) {
+	recorder := pageScript{Name: "recorder", Source: defaultRecorderScript, Required: true}
+	if bot.Script != "" {
+		data, err := os.ReadFile(bot.Script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recorder script: %v", err)
+		}
+		recorder.Source = string(data)
+	}
+
+	scripts := []pageScript{recorder}
+	for _, path := range bot.Plugins {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plugin: %v", err)
+		}
+		scripts = append(scripts, pageScript{
+			Name:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Source: string(data),
+		})
+	}
+	return scripts, nil
+}
+
+// configScript передает странице параметры записи и транспорта чанков
+// в window.ssbot_config; выполняется раньше скрипта записи
+func (bot *Bot) configScript() (pageScript, error) {
+	cfg := map[string]interface{}{
+		"recording": bot.Recording.pageConfig(),
+	}
+	if err := bot.addUploadConfig(cfg); err != nil {
+		return pageScript{}, err
+	}
+
+	data, _ := json.Marshal(cfg)
+	return pageScript{
+		Name:     "config",
+		Source:   fmt.Sprintf("window.ssbot_config = %s;", data),
+		Required: true,
+	}, nil
+}
+
+// injectScripts выполняет скрипт записи и плагины, затем проверяет версию
+// протокола и запоминает возможности скрипта. Уже выполненные на странице
+// скрипты повторно не запускаются.
+func (bot *Bot) injectScripts(scripts []pageScript) error {
+	for _, script := range scripts {
+		err := chromedp.Run(bot.Ctx, chromedp.Evaluate(wrapPageScript(script), nil))
+		if err == nil {
+			continue
+		}
+		// Без скрипта записи бот бесполезен, ошибка плагина не критична
+		if script.Required {
+			return fmt.Errorf("%s script failed: %v", script.Name, err)
+		}
+		log.Printf("Бот %s: ошибка плагина %s: %v", bot.ID, script.Name, err)
+		bot.Events.Add("error", fmt.Sprintf("plugin %s failed: %v", script.Name, err))
+	}
+
+	var info ScriptInfo
+	err := chromedp.Run(bot.Ctx, chromedp.Evaluate(`window.ssbot && ssbot.handshake ? ssbot.handshake() : null`, &info))
+	if err != nil {
+		return fmt.Errorf("script handshake failed: %v", err)
+	}
+	if info.Version == 0 {
+		return errors.New("recorder script does not provide ssbot.handshake")
+	}
+	if info.Version != scriptProtocolVersion {
+		return fmt.Errorf("recorder script protocol version %d is not supported (expected %d)", info.Version, scriptProtocolVersion)
+	}
+
+	bot.mu.Lock()
+	bot.scriptInfo = info
+	bot.mu.Unlock()
+
+	log.Printf("Бот %s: скрипт v%d, возможности: %s", bot.ID, info.Version, strings.Join(info.Capabilities, ", "))
+	bot.Events.Add("info", fmt.Sprintf("script v%d, capabilities: %s", info.Version, strings.Join(info.Capabilities, ", ")))
+	return nil
+}
+
+// Capabilities возвращает возможности внедренного скрипта
+func (bot *Bot) Capabilities() []string {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	return bot.scriptInfo.Capabilities
+}
+
+// HasCapability сообщает, поддерживает ли внедренный скрипт возможность
+func (bot *Bot) HasCapability(name string) bool {
+	return slices.Contains(bot.Capabilities(), name)
+}
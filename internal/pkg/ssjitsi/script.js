@@ -0,0 +1,826 @@
+// Версия протокола между скриптом и Go стороной. Меняется при
+// несовместимых изменениях привязок и формата данных.
+window.ssbot = window.ssbot || { plugins: {} };
+window.ssbot.version = 1;
+window.ssbot.capabilities = ['audio', 'levels'];
+if (window.ssbot_announcer) {
+    window.ssbot.capabilities.push('announce');
+}
+if (window.ssbot_config && window.ssbot_config.uploadUrl) {
+    window.ssbot.capabilities.push('binary-upload');
+}
+if (window.ssbot_config && window.ssbot_config.recording && window.ssbot_config.recording.roomMix) {
+    window.ssbot.capabilities.push('room_mix');
+}
+if (window.ssbot_config && window.ssbot_config.recording && window.ssbot_config.recording.video) {
+    window.ssbot.capabilities.push('video');
+}
+if (window.ssbot_config && window.ssbot_config.recording && window.ssbot_config.recording.screenShare) {
+    window.ssbot.capabilities.push('screenshare');
+}
+
+// Регистрация плагина: имя, версия и список возможностей
+window.ssbot.registerPlugin = function (name, info) {
+    this.plugins[name] = Object.assign({ version: 0, capabilities: [] }, info || {});
+};
+
+// Ответ на проверку со стороны Go: версия и все возможности, включая плагины
+window.ssbot.handshake = function () {
+    const capabilities = new Set(this.capabilities);
+    for (const name in this.plugins) {
+        for (const c of this.plugins[name].capabilities) {
+            capabilities.add(c);
+        }
+    }
+    return {
+        version: this.version,
+        capabilities: Array.from(capabilities),
+        plugins: Object.keys(this.plugins)
+    };
+};
+
+// Параметры записи, переданные из Go в window.ssbot_config
+function recordingConfig() {
+    const rec = (window.ssbot_config && window.ssbot_config.recording) || {};
+    return {
+        timeslice: rec.timeslice || 10000,
+        mimeType: rec.mimeType || 'audio/webm;codecs=opus',
+        audioBitsPerSecond: rec.audioBitsPerSecond || 0,
+        channels: rec.channels || 0,
+        roomMix: !!rec.roomMix,
+        audio: rec.audio !== false,
+        video: !!rec.video,
+        screenShare: !!rec.screenShare,
+        videoMimeType: rec.videoMimeType || 'video/webm;codecs=vp8',
+        videoBitsPerSecond: rec.videoBitsPerSecond || 0,
+        maxWidth: rec.maxWidth || 1280,
+        maxHeight: rec.maxHeight || 720,
+        maxFrameRate: rec.maxFrameRate || 15
+    };
+}
+
+function recorderOptions(rec) {
+    const options = { mimeType: rec.mimeType };
+    if (rec.audioBitsPerSecond) {
+        options.audioBitsPerSecond = rec.audioBitsPerSecond;
+    }
+    return options;
+}
+
+// Проверка параметров записи: поддерживает ли браузер тип и какие значения
+// MediaRecorder применит на самом деле
+window.ssbot.recordingSettings = function () {
+    const rec = recordingConfig();
+    if (!MediaRecorder.isTypeSupported(rec.mimeType)) {
+        return { supported: false, mimeType: rec.mimeType, audioBitsPerSecond: 0 };
+    }
+    const ctx = new AudioContext();
+    const probe = new MediaRecorder(ctx.createMediaStreamDestination().stream, recorderOptions(rec));
+    const res = { supported: true, mimeType: probe.mimeType || rec.mimeType, audioBitsPerSecond: probe.audioBitsPerSecond };
+    ctx.close();
+    res.videoSupported = MediaRecorder.isTypeSupported(rec.videoMimeType);
+    res.videoMimeType = rec.videoMimeType;
+    return res;
+};
+
+function wait(delayInMS) {
+    return new Promise((resolve) => setTimeout(resolve, delayInMS));
+}
+
+// Создание ссылки для скачивания
+function createDownloadLink(blob, t) {
+    const downloadLink = document.createElement('a');
+    downloadLink.href = URL.createObjectURL(blob);
+    downloadLink.download = 'recorded-audio.webm';
+    downloadLink.textContent = 'Скачать запись';
+
+    // Добавляем на страницу или заменяем существующую
+    const existingLink = document.getElementById('downloadLink');
+    if (existingLink) {
+        existingLink.replaceWith(downloadLink);
+    } else {
+        downloadLink.id = 'downloadLink';
+        t.appendChild(downloadLink);
+    }
+}
+
+// Отправка чанков одной дорожки в Go
+class ChunkSender {
+    constructor(meta) {
+        this.meta = meta;
+        this.queue = Promise.resolve();
+    }
+
+    // Чанки дорожки отправляются строго по очереди, иначе файл webm
+    // соберется в неправильном порядке
+    push(blob, extra) {
+        this.queue = this.queue
+            .then(() => this.send(blob, extra))
+            .catch((e) => console.error('chunk send failed:', e));
+    }
+
+    async send(blob, extra) {
+        const cfg = window.ssbot_config || {};
+        const meta = Object.assign(this.meta(), extra || {});
+        if (cfg.uploadUrl && meta.userid) {
+            try {
+                await this.upload(cfg, meta, blob);
+                return;
+            } catch (e) {
+                // Приемник дописывает чанк только целиком, поэтому после
+                // ошибки его можно повторить через привязку без дублей
+                console.error('upload failed, falling back to binding:', e);
+            }
+        }
+        await this.bind(meta, blob);
+    }
+
+    // Сырые байты по HTTP на loopback приемник, метаданные в заголовках
+    async upload(cfg, meta, blob) {
+        const headers = {
+            'Authorization': 'Bearer ' + cfg.uploadToken,
+            'Content-Type': 'application/octet-stream',
+            'X-Ssbot-Track': meta.u,
+            'X-Ssbot-User-Id': meta.userid,
+            'X-Ssbot-User': encodeURIComponent(meta.user || ''),
+            'X-Ssbot-Room': encodeURIComponent(meta.room),
+            'X-Ssbot-My-Id': meta.myid,
+            'X-Ssbot-Kind': meta.kind || 'audio',
+            'X-Ssbot-Source': meta.source || 'microphone'
+        };
+        if (meta.levels) {
+            headers['X-Ssbot-Levels'] = JSON.stringify(meta.levels);
+        }
+        const resp = await fetch(cfg.uploadUrl, {
+            method: 'POST',
+            headers: headers,
+            body: blob
+        });
+        if (!resp.ok) {
+            throw new Error('upload status ' + resp.status);
+        }
+    }
+
+    // Запасной путь: base64 через привязку CDP
+    bind(meta, blob) {
+        return new Promise((resolve) => {
+            const reader = new FileReader();
+            reader.onloadend = () => {
+                if (window.APP && window.APP.conference) {
+                    meta.d = reader.result.split(',')[1];
+                    window.ssbot_writeSound(JSON.stringify(meta));
+                }
+                resolve();
+            };
+            reader.readAsDataURL(blob);
+        });
+    }
+}
+
+// Общая дорожка комнаты: все remoteAudio_* смешиваются в одном AudioContext
+// и пишутся отдельным MediaRecorder. Участники подключаются и отключаются
+// от смесителя, а сам поток не прерывается.
+class RoomMix {
+    constructor() {
+        this.context = new AudioContext();
+        this.destination = this.context.createMediaStreamDestination();
+        this.sources = {};
+        this.sender = new ChunkSender(() => {
+            const conf = window.APP && window.APP.conference;
+            return {
+                myid: conf && conf.getMyUserId ? conf.getMyUserId() : 'unknown',
+                room: (conf && conf.roomName) || 'unknown',
+                userid: 'room_mix',
+                user: 'Room mix',
+                u: 'room_mix',
+                kind: 'audio',
+                source: 'mix'
+            };
+        });
+    }
+
+    start() {
+        const rec = recordingConfig();
+        this.context.resume();
+        this.recorder = new MediaRecorder(this.destination.stream, recorderOptions(rec));
+        this.recorder.ondataavailable = (e) => {
+            if (e.data.size > 0) {
+                this.sender.push(e.data);
+            }
+        };
+        this.recorder.start(rec.timeslice);
+    }
+
+    add(element) {
+        if (this.sources[element.id]) {
+            return;
+        }
+        try {
+            const stream = element.srcObject instanceof MediaStream ? element.srcObject : element.captureStream();
+            const source = this.context.createMediaStreamSource(stream);
+            source.connect(this.destination);
+            this.sources[element.id] = source;
+        } catch (e) {
+            // У элемента еще нет звуковой дорожки - подключим при следующем событии
+            console.error('room mix: cannot add ' + element.id + ':', e);
+        }
+    }
+
+    remove(element) {
+        const source = this.sources[element.id];
+        if (source) {
+            source.disconnect();
+            delete this.sources[element.id];
+        }
+    }
+}
+
+// Запись удаленной видеодорожки (камера или демонстрация экрана). Кадры
+// рисуются на canvas с ограничением размера и частоты, поток canvas пишет
+// MediaRecorder.
+class VideoCapture {
+    constructor(participant, track) {
+        this.participant = participant;
+        this.track = track;
+        this.source = track.getVideoType && track.getVideoType() === 'desktop' ? 'desktop' : 'camera';
+        this.id = videoTrackId(track);
+        this.sender = new ChunkSender(() => {
+            const conf = window.APP && window.APP.conference;
+            return {
+                myid: conf && conf.getMyUserId ? conf.getMyUserId() : 'unknown',
+                room: (conf && conf.roomName) || 'unknown',
+                userid: this.participant.getId(),
+                user: this.participant.getDisplayName() || '',
+                u: this.source + '_' + this.id.replace(/[^A-Za-z0-9_-]/g, '_'),
+                kind: 'video',
+                source: this.source
+            };
+        });
+    }
+
+    start() {
+        const rec = recordingConfig();
+        this.video = document.createElement('video');
+        this.video.muted = true;
+        this.video.playsInline = true;
+        this.video.srcObject = new MediaStream([this.track.getTrack()]);
+        this.video.play().catch((e) => console.error('video play failed:', e));
+
+        this.canvas = document.createElement('canvas');
+        this.canvas.width = 2;
+        this.canvas.height = 2;
+        this.canvas2d = this.canvas.getContext('2d');
+        this.timer = setInterval(() => this.draw(rec), Math.round(1000 / rec.maxFrameRate));
+
+        const options = { mimeType: rec.videoMimeType };
+        if (rec.videoBitsPerSecond) {
+            options.videoBitsPerSecond = rec.videoBitsPerSecond;
+        }
+        this.recorder = new MediaRecorder(this.canvas.captureStream(rec.maxFrameRate), options);
+        this.recorder.ondataavailable = (e) => {
+            if (e.data.size > 0) {
+                this.sender.push(e.data);
+            }
+        };
+        this.recorder.start(rec.timeslice);
+    }
+
+    draw(rec) {
+        const w = this.video.videoWidth;
+        const h = this.video.videoHeight;
+        if (!w || !h) {
+            return;
+        }
+        // Кадр уменьшается до ограничений с сохранением пропорций; размеры
+        // четные, как требуют видеокодеки
+        const scale = Math.min(1, rec.maxWidth / w, rec.maxHeight / h);
+        const cw = Math.max(2, Math.round(w * scale / 2) * 2);
+        const ch = Math.max(2, Math.round(h * scale / 2) * 2);
+        if (this.canvas.width !== cw || this.canvas.height !== ch) {
+            this.canvas.width = cw;
+            this.canvas.height = ch;
+        }
+        this.canvas2d.drawImage(this.video, 0, 0, cw, ch);
+    }
+
+    stop() {
+        clearInterval(this.timer);
+        if (this.recorder.state !== 'inactive') {
+            this.recorder.stop();
+        }
+        this.video.srcObject = null;
+    }
+}
+
+function videoTrackId(track) {
+    return (track.getId && track.getId()) || track.getTrack().id;
+}
+
+// Панель статуса
+class StatusInfo extends HTMLElement {
+    constructor() {
+        super();
+    }
+
+    connectedCallback() {
+        this.shadow = this.attachShadow({ mode: "open" });
+        this.cont = this.shadow.appendChild(document.createElement("div"));
+        this.cont.style.width = "100%";
+        this.cont.style.height = "30px";
+        this.cont.style.border = "1px solid red";
+        this.cont.style.color = "black";
+        this.cont.style.display = "flex";
+        this.cont.style.flexDirection = "column";
+        this.cont.style.alignItems = "center";
+        this.cont.style.justifyContent = "center";
+        this.body = this.cont.appendChild(document.createElement("div"));
+    }
+
+    refresh() {
+        setTimeout(() => {
+            if (window.APP && window.APP.connection && window.APP.connection.getJid) {
+                this.body.innerHTML = `<div>${window.APP.connection.getJid()}</div>`;
+            } else {
+                this.body.innerHTML = `<div>Waiting for connection...</div>`;
+            }
+        }, 3000);
+    }
+
+}
+
+// Период замера уровня громкости участника, мс
+const LEVEL_STEP = 200;
+
+// Панель для информации о аудио потоке
+class AudioInfo extends HTMLElement {
+    constructor() {
+        super();
+        this.displayName = "";
+    }
+
+    connectedCallback() {
+        console.error("connectedCallback");
+        this.shadow = this.attachShadow({ mode: "open" });
+        this.cont = this.shadow.appendChild(document.createElement("div"));
+        this.cont.style.width = "100%";
+        this.cont.style.height = "30px";
+        this.cont.style.border = "1px solid green";
+        this.cont.style.color = "black";
+        this.cont.style.display = "flex";
+        this.cont.style.flexDirection = "column";
+        this.cont.style.alignItems = "center";
+        this.cont.style.justifyContent = "center";
+        this.body = this.cont.appendChild(document.createElement("div"));
+        this.root = this.cont.appendChild(document.createElement("div"));
+    }
+
+    ondataavailable(event) {
+        if (event.data.size > 0) {
+            console.error(event.data.size);
+            if (!this.sender) {
+                this.sender = new ChunkSender(() => this.chunkMeta());
+            }
+            this.sender.push(event.data, { levels: this.takeLevels() });
+        }
+    }
+
+    chunkMeta() {
+        const conf = window.APP && window.APP.conference;
+        return {
+            myid: conf && conf.getMyUserId ? conf.getMyUserId() : 'unknown',
+            room: (conf && conf.roomName) || 'unknown',
+            userid: this.userId,
+            user: this.displayName,
+            u: this.audioElement.id,
+            kind: 'audio',
+            source: 'microphone'
+        };
+    }
+
+    onstop(event) {
+        this.root.innerHTML = "stop";
+    }
+
+    syncInfo() {
+        if (!window.APP || !window.APP.conference || !window.APP.conference.listMembers) {
+            console.error("APP.conference not ready yet, will retry...");
+            // Попробуем еще раз через 2 секунды
+            if (!this.syncRetryCount) this.syncRetryCount = 0;
+            if (this.syncRetryCount < 10) {
+                this.syncRetryCount++;
+                setTimeout(() => this.syncInfo(), 2000);
+            }
+            return;
+        }
+
+        const lst = window.APP.conference.listMembers();
+        for (var m of lst) {
+            for (var t of m._tracks) {
+                if (t.type === "audio") {
+                    for (var c of t.containers) {
+                        if (c.id == this.audioElement.id) {
+                            this.displayName = m._displayName;
+                            this.userId = m._id;
+                            this.body.innerHTML = `<b>${this.displayName}</b>`;
+                            console.error(`syncInfo success: ${this.displayName} (${this.userId})`);
+                        }
+                    }
+                }
+            }
+        }
+    }
+
+    init(audioElement) {
+        this.audioElement = audioElement;
+        this.syncInfo();
+        this.root.innerHTML = "init";
+        this.recordedChunks = [];
+        // Инициализируем AudioContext при первом клике (из-за autoplay policy)
+        if (!this.audioContext && !this.initAudioContext()) {
+            alert('Не удалось инициализировать аудио контекст');
+            return false;
+        }
+
+        // Получаем аудиопоток из audio элемента; при заданном числе каналов
+        // поток проходит через AudioContext с нужной раскладкой
+        const rec = recordingConfig();
+        this.audioStream = rec.channels ? this.channelStream(rec.channels) : this.audioElement.captureStream();
+
+        // Создаем MediaRecorder с параметрами из Go
+        this.mediaRecorder = new MediaRecorder(this.audioStream, recorderOptions(rec));
+
+        this.mediaRecorder.onstart = (e) => {
+            this.root.innerHTML = "start";
+        }
+        this.mediaRecorder.ondataavailable = (e) => this.ondataavailable(e);
+        this.mediaRecorder.onstop = (e) => this.onstop(e);
+        return true;
+    }
+
+    initAudioContext() {
+        try {
+            // Создаем AudioContext
+            this.audioContext = new (window.AudioContext || window.webkitAudioContext)();
+
+            // Создаем источник из audio элемента
+            this.source = this.audioContext.createMediaElementSource(this.audioElement);
+
+            // Подключаем к выходу (динамикам)
+            this.source.connect(this.audioContext.destination);
+
+            console.error('+AudioContext');
+            return true;
+        } catch (error) {
+            console.error('err AudioContext:', error);
+            return false;
+        }
+    }
+
+    channelStream(channels) {
+        const dest = this.audioContext.createMediaStreamDestination();
+        dest.channelCount = channels;
+        dest.channelCountMode = 'explicit';
+        dest.channelInterpretation = 'speakers';
+        this.source.connect(dest);
+        return dest.stream;
+    }
+
+    startRecording() {
+        this.mediaRecorder.start(recordingConfig().timeslice);
+        this.startLevels();
+    }
+
+    stopRecording() {
+        clearInterval(this.levelTimer);
+        this.mediaRecorder.stop();
+    }
+
+    // Уровень громкости (RMS) снимается каждые LEVEL_STEP мс и уходит в Go
+    // вместе с чанком; по нему строится шкала активности говорящих
+    startLevels() {
+        this.analyser = this.audioContext.createAnalyser();
+        this.analyser.fftSize = 1024;
+        this.source.connect(this.analyser);
+        this.levelBuf = new Float32Array(this.analyser.fftSize);
+        this.levels = { t: Date.now(), step: LEVEL_STEP, v: [] };
+        this.levelTimer = setInterval(() => {
+            this.analyser.getFloatTimeDomainData(this.levelBuf);
+            let sum = 0;
+            for (const x of this.levelBuf) {
+                sum += x * x;
+            }
+            this.levels.v.push(Math.round(Math.sqrt(sum / this.levelBuf.length) * 1000) / 1000);
+        }, LEVEL_STEP);
+    }
+
+    takeLevels() {
+        const levels = this.levels;
+        if (!levels || levels.v.length === 0) {
+            return undefined;
+        }
+        this.levels = { t: levels.t + levels.v.length * LEVEL_STEP, step: LEVEL_STEP, v: [] };
+        return levels;
+    }
+}
+customElements.define("ssbot-audio", AudioInfo);
+customElements.define("ssbot-info", StatusInfo);
+
+// Функция для отслеживания появления/исчезновения элементов
+function observeElements(selector, callback) {
+
+    const infopanel = document.body.appendChild(document.createElement("div"));
+    infopanel.style.position = "absolute";
+    infopanel.style.top = "20px";
+    infopanel.style.left = "20px";
+    infopanel.style.minHeight = "50px";
+    infopanel.style.minWidth = "50px";
+    infopanel.style.maxWidth = "400px";
+    infopanel.style.padding = "4px";
+    infopanel.style.backgroundColor = "white";
+    infopanel.innerHTML = "<h5>BotPanel</h5>";
+    infopanel.id = "ssbot_panel";
+
+    const ssbotinfo = infopanel.appendChild(document.createElement("ssbot-info"));
+    ssbotinfo.refresh();
+
+    const targetNode = document.body;
+    const config = {
+        childList: true,
+        subtree: true
+    };
+
+    const observer = new MutationObserver((mutations) => {
+        mutations.forEach((mutation) => {
+            // Проверяем добавленные узлы
+            mutation.addedNodes.forEach((node) => {
+                if (node.nodeType === 1) { // Проверяем, что это элемент
+                    if (node.matches(selector)) {
+                        callback(node, 'appeared');
+                    }
+                    // Проверяем дочерние элементы добавленного узла
+                    const matchingElements = node.querySelectorAll(selector);
+                    matchingElements.forEach(el => callback(el, 'appeared'));
+                }
+            });
+
+            // Проверяем удаленные узлы
+            mutation.removedNodes.forEach((node) => {
+                if (node.nodeType === 1) {
+                    if (node.matches(selector)) {
+                        callback(node, 'disappeared');
+                    }
+                    // Проверяем дочерние элементы удаленного узла
+                    const matchingElements = node.querySelectorAll(selector);
+                    matchingElements.forEach(el => callback(el, 'disappeared'));
+                }
+            });
+        });
+    });
+
+    observer.observe(targetNode, config);
+    return observer;
+}
+
+// Пример использования
+const observer = observeElements('audio', (element, event) => {
+    if (event === 'appeared') {
+        console.error('+:' + element.id);
+        if (element.id.startsWith("remoteAudio_")) {
+            handleElementAppeared(element);
+        }
+    } else if (event === 'disappeared') {
+        console.error('-:' + element.id);
+        if (element.id.startsWith("remoteAudio_")) {
+            handleElementDisappeared(element);
+        }
+    }
+});
+
+let audios = {};
+
+// Общая дорожка комнаты, если включена в параметрах записи
+let roomMix = null;
+if (recordingConfig().roomMix) {
+    roomMix = new RoomMix();
+    roomMix.start();
+}
+
+function handleElementAppeared(element) {
+    if (roomMix) {
+        roomMix.add(element);
+    }
+    // Запись аудио участников может быть выключена, тогда пишется только room_mix
+    if (!recordingConfig().audio) {
+        return;
+    }
+    const i = document.getElementById("ssbot_panel").appendChild(document.createElement("ssbot-audio"));
+    audios[element.id] = i;
+    i.init(element);
+    i.startRecording();
+}
+
+function handleElementDisappeared(element) {
+    if (roomMix) {
+        roomMix.remove(element);
+    }
+    if (!audios[element.id]) {
+        return;
+    }
+    console.error("mr stop: " + audios[element.id].mediaRecorder.state);
+    audios[element.id].stopRecording();
+    audios[element.id].remove();
+    delete audios[element.id];
+}
+
+// Видеодорожки берутся из lib-jitsi-meet: у удаленных участников нет
+// отдельных video элементов для каждой дорожки
+let videos = {};
+
+function syncVideoTracks() {
+    const rec = recordingConfig();
+    const room = window.APP && APP.conference && APP.conference._room;
+    if (!room) {
+        return;
+    }
+    const seen = {};
+    for (const p of room.getParticipants()) {
+        for (const t of p.getTracks()) {
+            if (t.getType() !== 'video' || !t.getTrack()) {
+                continue;
+            }
+            const desktop = t.getVideoType && t.getVideoType() === 'desktop';
+            if (desktop ? !rec.screenShare : !rec.video) {
+                continue;
+            }
+            const id = videoTrackId(t);
+            seen[id] = true;
+            if (!videos[id]) {
+                videos[id] = new VideoCapture(p, t);
+                videos[id].start();
+            }
+        }
+    }
+    for (const id in videos) {
+        if (!seen[id]) {
+            videos[id].stop();
+            delete videos[id];
+        }
+    }
+}
+
+if (recordingConfig().video || recordingConfig().screenShare) {
+    setInterval(syncVideoTracks, 2000);
+}
+
+// Время входа участников: по событию USER_JOINED, а для тех, кто был в
+// комнате до бота, - момент, когда бот их увидел
+const joinedAt = {};
+
+function trackJoins() {
+    const conf = window.APP && APP.conference;
+    if (!conf || !conf.listMembers) {
+        return;
+    }
+    for (const m of conf.listMembers()) {
+        if (!joinedAt[m.getId()]) {
+            joinedAt[m.getId()] = Date.now();
+        }
+    }
+}
+
+if (window.APP && APP.conference && APP.conference._room && window.JitsiMeetJS) {
+    APP.conference._room.on(JitsiMeetJS.events.conference.USER_JOINED, (id) => {
+        joinedAt[id] = Date.now();
+    });
+}
+trackJoins();
+setInterval(trackJoins, 2000);
+
+// Список участников для Go: роль, состояние микрофона и камеры, соединение
+// и записывается ли участник
+window.ssbot.participants = function () {
+    const conf = window.APP && APP.conference;
+    if (!conf || !conf.listMembers) {
+        return [];
+    }
+    trackJoins();
+    const recordedAudio = {};
+    for (const id in audios) {
+        const a = audios[id];
+        if (a.userId && a.mediaRecorder && a.mediaRecorder.state === 'recording') {
+            recordedAudio[a.userId] = true;
+        }
+    }
+    const recordedVideo = {};
+    for (const id in videos) {
+        recordedVideo[videos[id].participant.getId()] = true;
+    }
+    return conf.listMembers().map((m) => ({
+        id: m.getId(),
+        displayName: m.getDisplayName() || '',
+        role: m.getRole ? m.getRole() : '',
+        audioMuted: m.isAudioMuted ? m.isAudioMuted() : false,
+        videoMuted: m.isVideoMuted ? m.isVideoMuted() : false,
+        connectionStatus: m.getConnectionStatus ? m.getConnectionStatus() : '',
+        joinedAt: joinedAt[m.getId()] || 0,
+        recordedAudio: !!recordedAudio[m.getId()],
+        recordedVideo: !!recordedVideo[m.getId()]
+    }));
+};
+window.ssbot.capabilities.push('participants');
+
+// Качество соединения: входящее аудио из getStats() активного
+// RTCPeerConnection. Счетчики переводятся в значения за время с прошлого
+// вызова, поэтому первый вызов только запоминает их.
+let statsPrev = {};
+
+function activePeerConnection(room) {
+    const session = room.isP2PActive && room.isP2PActive() ? room.p2pJingleSession : room.jvbJingleSession;
+    const tpc = session && session.peerconnection;
+    return tpc && tpc.peerconnection;
+}
+
+window.ssbot.stats = async function () {
+    const room = window.APP && APP.conference && APP.conference._room;
+    if (!room || !room.isJoined || !room.isJoined()) {
+        return [];
+    }
+    const pc = activePeerConnection(room);
+    if (!pc || !pc.getStats) {
+        return [];
+    }
+
+    // Владельцы входящих аудио потоков по ssrc и id дорожки
+    const owners = {};
+    for (const p of room.getParticipants()) {
+        for (const t of p.getTracks()) {
+            if (t.getType() !== 'audio') {
+                continue;
+            }
+            const owner = { id: p.getId(), displayName: p.getDisplayName() || '' };
+            if (t.getSSRC && t.getSSRC()) {
+                owners['ssrc:' + t.getSSRC()] = owner;
+            }
+            if (t.getTrack && t.getTrack()) {
+                owners['track:' + t.getTrack().id] = owner;
+            }
+        }
+    }
+
+    const report = await pc.getStats();
+    // RTT соединения; при JVB это задержка до моста, общая для всех
+    let connectionRtt = 0;
+    report.forEach((s) => {
+        if (s.type === 'candidate-pair' && s.state === 'succeeded' && s.nominated && s.currentRoundTripTime) {
+            connectionRtt = s.currentRoundTripTime * 1000;
+        }
+    });
+
+    const now = Date.now();
+    const next = {};
+    const res = [];
+    report.forEach((s) => {
+        if (s.type !== 'inbound-rtp' || s.kind !== 'audio') {
+            return;
+        }
+        const owner = owners['ssrc:' + s.ssrc] || owners['track:' + s.trackIdentifier];
+        if (!owner) {
+            return;
+        }
+        const cur = {
+            t: now,
+            lost: Math.max(0, s.packetsLost || 0),
+            received: s.packetsReceived || 0,
+            bytes: s.bytesReceived || 0,
+            concealed: s.concealedSamples || 0,
+            samples: s.totalSamplesReceived || 0
+        };
+        next[s.id] = cur;
+        const prev = statsPrev[s.id];
+        if (!prev || cur.t <= prev.t) {
+            return;
+        }
+        const remote = s.remoteId && report.get(s.remoteId);
+        const lost = Math.max(0, cur.lost - prev.lost);
+        const received = Math.max(0, cur.received - prev.received);
+        const concealed = Math.max(0, cur.concealed - prev.concealed);
+        const samples = Math.max(0, cur.samples - prev.samples);
+        res.push({
+            id: owner.id,
+            displayName: owner.displayName,
+            packetsReceived: received,
+            packetsLost: lost,
+            packetLoss: lost + received > 0 ? lost * 100 / (lost + received) : 0,
+            jitterMs: (s.jitter || 0) * 1000,
+            bitrateKbps: Math.max(0, cur.bytes - prev.bytes) * 8 / (cur.t - prev.t),
+            concealedSamples: concealed,
+            samplesReceived: samples,
+            concealedPercent: samples > 0 ? concealed * 100 / samples : 0,
+            rttMs: remote && remote.roundTripTime ? remote.roundTripTime * 1000 : connectionRtt
+        });
+    });
+    statsPrev = next;
+    return res;
+};
+window.ssbot.capabilities.push('stats');
+"";
\ No newline at end of file
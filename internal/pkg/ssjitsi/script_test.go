@@ -0,0 +1,76 @@
+package ssjitsi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadScripts(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, source string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	custom := write("custom.js", "window.custom = true;")
+	notes := write("notes.js", "window.notes = true;")
+	layout := write("layout.plugin.js", "window.layout = true;")
+
+	tests := []struct {
+		name    string
+		bot     *Bot
+		want    []string // Имена скриптов по порядку
+		source  string   // Начало исходника скрипта записи
+		wantErr string
+	}{
+		{"embedded recorder", &Bot{}, []string{"recorder"}, defaultRecorderScript, ""},
+		{"custom recorder", &Bot{Script: custom}, []string{"recorder"}, "window.custom", ""},
+		{"plugins after recorder", &Bot{Plugins: []string{notes, layout}}, []string{"recorder", "notes", "layout.plugin"}, defaultRecorderScript, ""},
+		{"missing recorder", &Bot{Script: filepath.Join(dir, "missing.js")}, nil, "", "failed to read recorder script"},
+		{"missing plugin", &Bot{Plugins: []string{notes, filepath.Join(dir, "missing.js")}}, nil, "", "failed to read plugin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scripts, err := tt.bot.loadScripts()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadScripts() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := make([]string, 0, len(scripts))
+			for _, s := range scripts {
+				names = append(names, s.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tt.want) {
+				t.Errorf("scripts = %v, want %v", names, tt.want)
+			}
+			if !scripts[0].Required || !strings.HasPrefix(scripts[0].Source, tt.source) {
+				t.Errorf("recorder = %+v", scripts[0])
+			}
+			for _, s := range scripts[1:] {
+				if s.Required {
+					t.Errorf("plugin %s is required", s.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedScriptVersion(t *testing.T) {
+	want := fmt.Sprintf("window.ssbot.version = %d;", scriptProtocolVersion)
+	if !strings.Contains(defaultRecorderScript, want) {
+		t.Errorf("embedded recorder script does not declare %q", want)
+	}
+	if !strings.Contains(defaultRecorderScript, "window.ssbot.handshake = function") {
+		t.Error("embedded recorder script does not provide ssbot.handshake")
+	}
+}
@@ -0,0 +1,134 @@
+package ssjitsi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SessionInfo описывает каталог сессии записи: {DataDir}/{room}/{session-id}
+type SessionInfo struct {
+	ID       string    `json:"id"`
+	Room     string    `json:"room"`
+	Files    int       `json:"files"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// SessionFile описывает файл внутри сессии
+type SessionFile struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+var errSessionNotFound = errors.New("session not found")
+
+// listSessions обходит каталоги данных ботов и собирает список сессий
+func listSessions(dataDirs []string) []SessionInfo {
+	sessions := make([]SessionInfo, 0)
+	for _, dataDir := range dataDirs {
+		rooms, err := os.ReadDir(dataDir)
+		if err != nil {
+			continue
+		}
+		for _, room := range rooms {
+			if !room.IsDir() {
+				continue
+			}
+			entries, err := os.ReadDir(filepath.Join(dataDir, room.Name()))
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					continue
+				}
+				dir := filepath.Join(dataDir, room.Name(), entry.Name())
+				info := SessionInfo{ID: entry.Name(), Room: room.Name()}
+				if name, err := os.ReadFile(filepath.Join(dir, "room.json")); err == nil {
+					info.Room = string(name)
+				}
+				for _, f := range listSessionFiles(dir) {
+					info.Files++
+					info.Size += f.Size
+					if f.Modified.After(info.Modified) {
+						info.Modified = f.Modified
+					}
+				}
+				sessions = append(sessions, info)
+			}
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Modified.After(sessions[j].Modified)
+	})
+	return sessions
+}
+
+// findSession возвращает путь к каталогу сессии по её ID
+func findSession(dataDirs []string, sid string) (string, error) {
+	if sid == "" || sid != SafeFilename(sid) {
+		return "", errSessionNotFound
+	}
+	// Имена сравниваются точно: sid приходит от клиента и не должен
+	// работать как шаблон
+	for _, dataDir := range dataDirs {
+		rooms, err := os.ReadDir(dataDir)
+		if err != nil {
+			continue
+		}
+		for _, room := range rooms {
+			if !room.IsDir() {
+				continue
+			}
+			entries, err := os.ReadDir(filepath.Join(dataDir, room.Name()))
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if entry.IsDir() && entry.Name() == sid {
+					return filepath.Join(dataDir, room.Name(), entry.Name()), nil
+				}
+			}
+		}
+	}
+	return "", errSessionNotFound
+}
+
+// listSessionFiles возвращает файлы сессии с путями относительно её каталога
+func listSessionFiles(dir string) []SessionFile {
+	files := make([]SessionFile, 0)
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		st, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files = append(files, SessionFile{
+			Name:     filepath.ToSlash(rel),
+			Size:     st.Size(),
+			Modified: st.ModTime(),
+		})
+		return nil
+	})
+	return files
+}
+
+// sessionFilePath проверяет имя файла и возвращает его путь внутри каталога сессии
+func sessionFilePath(dir, name string) (string, error) {
+	name = strings.TrimPrefix(name, "/")
+	clean := filepath.Clean(filepath.FromSlash(name))
+	parent := clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator))
+	if name == "" || clean == "." || parent || filepath.IsAbs(clean) {
+		return "", errors.New("invalid file name")
+	}
+	return filepath.Join(dir, clean), nil
+}
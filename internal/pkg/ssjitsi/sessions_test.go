@@ -0,0 +1,104 @@
+package ssjitsi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSessionFilePath(t *testing.T) {
+	dir := filepath.Join("data", "room", "session")
+	tests := []struct {
+		name    string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{"plain file", "track.webm", "track.webm", false},
+		{"nested file", "screenshots/0001.png", filepath.Join("screenshots", "0001.png"), false},
+		{"leading slash", "/track.webm", "track.webm", false},
+		{"inner dots stay inside", "screenshots/../track.webm", "track.webm", false},
+		{"name starting with dots", "..notes", "..notes", false},
+		{"dir starting with dots", "..cache/track.webm", filepath.Join("..cache", "track.webm"), false},
+		{"empty", "", "", true},
+		{"only slash", "/", "", true},
+		{"current dir", ".", "", true},
+		{"parent dir", "..", "", true},
+		{"parent traversal", "../other/track.webm", "", true},
+		{"deep traversal", "../../../etc/passwd", "", true},
+		{"traversal after subdir", "screenshots/../../other/track.webm", "", true},
+		{"absolute after slash trim", "//etc/passwd", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sessionFilePath(dir, tt.file)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("sessionFilePath(%q) = %q, want error", tt.file, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(dir, tt.want); got != want {
+				t.Errorf("sessionFilePath(%q) = %q, want %q", tt.file, got, want)
+			}
+		})
+	}
+}
+
+func TestFindSession(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	for _, dir := range []string{
+		filepath.Join(first, "room1", "s1"),
+		filepath.Join(second, "room2", "s2"),
+		filepath.Join(second, "room2", "s1x"),
+		filepath.Join(second, "room2", "s[1]"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Файл с именем сессии не должен приниматься за сессию
+	if err := os.WriteFile(filepath.Join(first, "room1", "s3"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		sid  string
+		want string
+	}{
+		{"first data dir", "s1", filepath.Join(first, "room1", "s1")},
+		{"second data dir", "s2", filepath.Join(second, "room2", "s2")},
+		{"file is not a session", "s3", ""},
+		{"unknown", "s4", ""},
+		{"empty", "", ""},
+		{"bracket in name", "s[1]", filepath.Join(second, "room2", "s[1]")},
+		{"wildcard", "*", ""},
+		{"single char wildcard", "s?", ""},
+		{"prefix wildcard", "s1*", ""},
+		{"character class", "s[2]", ""},
+		{"broken pattern", "s[", ""},
+		{"parent dir", "..", ""},
+		{"traversal", "../room1/s1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findSession([]string{first, second}, tt.sid)
+			if tt.want == "" {
+				if err != errSessionNotFound {
+					t.Fatalf("findSession(%q) = %q, %v, want errSessionNotFound", tt.sid, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("findSession(%q) = %q, want %q", tt.sid, got, tt.want)
+			}
+		})
+	}
+}
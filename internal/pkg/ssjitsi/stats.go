@@ -0,0 +1,326 @@
+package ssjitsi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Временной ряд качества соединения в каталоге сессии
+const statsFile = "stats.jsonl"
+
+// Параметры сбора статистики по умолчанию
+const (
+	defaultStatsInterval = 5 * time.Second
+	statsSummaryInterval = time.Minute // Как часто обновлять сводку в манифесте
+)
+
+// Пороги, после которых интервал считается плохим
+const (
+	poorPacketLoss = 5.0  // Потери пакетов, %
+	poorJitterMs   = 30.0 // Джиттер, мс
+	poorConcealed  = 5.0  // Восстановленные декодером сэмплы, %
+)
+
+var ErrStatsDisabled = errors.New("connection statistics are disabled for this bot")
+
+// StatsOptions управляет сбором статистики WebRTC по входящему аудио
+type StatsOptions struct {
+	Disabled bool          `yaml:"Disabled"` // Не собирать статистику
+	Interval time.Duration `yaml:"Interval"` // Период замеров (по умолчанию 5s)
+}
+
+// validate проверяет параметры статистики при загрузке конфигурации
+func (o StatsOptions) validate() error {
+	if o.Interval < 0 || (o.Interval > 0 && o.Interval < time.Second) {
+		return fmt.Errorf("Stats.Interval %s is too small", o.Interval)
+	}
+	return nil
+}
+
+// interval возвращает период замеров с учетом значения по умолчанию
+func (o StatsOptions) interval() time.Duration {
+	if o.Interval == 0 {
+		return defaultStatsInterval
+	}
+	return o.Interval
+}
+
+// ParticipantStats - качество входящего аудио участника за интервал замера
+type ParticipantStats struct {
+	ID               string  `json:"id"`
+	DisplayName      string  `json:"displayName"`
+	PacketsReceived  int64   `json:"packetsReceived"`
+	PacketsLost      int64   `json:"packetsLost"`
+	PacketLoss       float64 `json:"packetLoss"` // Потери пакетов, %
+	JitterMs         float64 `json:"jitterMs"`
+	BitrateKbps      float64 `json:"bitrateKbps"`
+	ConcealedSamples int64   `json:"concealedSamples"` // Сэмплы, восстановленные декодером вместо потерянных
+	SamplesReceived  int64   `json:"samplesReceived"`
+	ConcealedPercent float64 `json:"concealedPercent"`
+	RttMs            float64 `json:"rttMs"` // При JVB - задержка до моста
+}
+
+// StatsSample - один замер по всем участникам, строка stats.jsonl
+type StatsSample struct {
+	Time         time.Time          `json:"time"`
+	Participants []ParticipantStats `json:"participants"`
+}
+
+// QualitySummary - сводка качества соединения за сессию
+type QualitySummary struct {
+	Samples      int                  `json:"samples"`
+	From         time.Time            `json:"from"`
+	To           time.Time            `json:"to"`
+	Participants []ParticipantQuality `json:"participants"`
+}
+
+// ParticipantQuality - сводка качества входящего аудио участника
+type ParticipantQuality struct {
+	ID               string  `json:"id"`
+	DisplayName      string  `json:"displayName"`
+	Samples          int     `json:"samples"`
+	PacketLoss       float64 `json:"packetLoss"` // Потери пакетов за сессию, %
+	MaxPacketLoss    float64 `json:"maxPacketLoss"`
+	AvgJitterMs      float64 `json:"avgJitterMs"`
+	MaxJitterMs      float64 `json:"maxJitterMs"`
+	AvgBitrateKbps   float64 `json:"avgBitrateKbps"`
+	ConcealedPercent float64 `json:"concealedPercent"`
+	AvgRttMs         float64 `json:"avgRttMs"`
+	MaxRttMs         float64 `json:"maxRttMs"`
+	PoorPercent      float64 `json:"poorPercent"` // Доля интервалов с потерями, джиттером или восстановлением выше порогов
+}
+
+// Запись в stats.jsonl из горутины бота и чтение из API
+var statsMu sync.Mutex
+
+// collectStats снимает статистику со страницы
+func (bot *Bot) collectStats(ctx context.Context) (StatsSample, error) {
+	sample := StatsSample{Time: time.Now()}
+	err := chromedp.Run(ctx, chromedp.Evaluate(`ssbot.stats()`, &sample.Participants, evalAwait))
+	if err != nil {
+		return sample, fmt.Errorf("failed to collect connection statistics: %v", err)
+	}
+	if sample.Participants == nil {
+		sample.Participants = make([]ParticipantStats, 0)
+	}
+	return sample, nil
+}
+
+// runStats собирает статистику, пока открыта вкладка бота, дописывает
+// замеры в stats.jsonl и обновляет сводку качества в манифесте
+func (bot *Bot) runStats(ctx context.Context) {
+	if bot.Stats.Disabled || !bot.HasCapability("stats") {
+		return
+	}
+	bot.mu.Lock()
+	bot.lastStats = nil
+	bot.mu.Unlock()
+
+	ticker := time.NewTicker(bot.Stats.interval())
+	defer ticker.Stop()
+	summary := time.NewTicker(statsSummaryInterval)
+	defer summary.Stop()
+	defer bot.writeQualitySummary()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-summary.C:
+			bot.writeQualitySummary()
+		case <-ticker.C:
+			if bot.GetStatus() != StatusRunning {
+				continue
+			}
+			sample, err := bot.collectStats(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Бот %s: %v", bot.ID, err)
+				}
+				continue
+			}
+			bot.mu.Lock()
+			bot.lastStats = &sample
+			bot.mu.Unlock()
+			if len(sample.Participants) == 0 {
+				continue
+			}
+			if err := appendStats(bot.SessionDir(), sample); err != nil {
+				log.Printf("Бот %s: не удалось записать статистику: %v", bot.ID, err)
+			}
+		}
+	}
+}
+
+// CurrentStats возвращает последний замер статистики бота
+func (bot *Bot) CurrentStats() (*StatsSample, error) {
+	if bot.Stats.Disabled {
+		return nil, ErrStatsDisabled
+	}
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	if bot.lastStats == nil {
+		return nil, errors.New("connection statistics are not collected yet")
+	}
+	return bot.lastStats, nil
+}
+
+// appendStats дописывает замер в stats.jsonl
+func appendStats(dir string, sample StatsSample) error {
+	if dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(dir, statsFile), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// writeQualitySummary пересчитывает сводку по stats.jsonl и сохраняет ее в
+// манифест. Сводка строится по файлу, поэтому учитывает и прошлые запуски
+// бота в той же сессии.
+func (bot *Bot) writeQualitySummary() {
+	dir := bot.SessionDir()
+	if dir == "" {
+		return
+	}
+	summary, err := sessionQuality(dir)
+	if err != nil {
+		log.Printf("Бот %s: не удалось построить сводку качества: %v", bot.ID, err)
+		return
+	}
+	if summary.Samples == 0 {
+		return
+	}
+	err = bot.updateManifest(func(m *SessionManifest) {
+		m.Quality = &summary
+	})
+	if err != nil {
+		log.Printf("Бот %s: не удалось обновить манифест: %v", bot.ID, err)
+	}
+}
+
+// qualityTotals - накопленные значения участника для сводки
+type qualityTotals struct {
+	quality   ParticipantQuality
+	received  int64
+	lost      int64
+	concealed int64
+	samples   int64
+	jitter    float64
+	bitrate   float64
+	rtt       float64
+	rttCount  int
+	poor      int
+}
+
+// sessionQuality строит сводку качества соединения по stats.jsonl
+func sessionQuality(dir string) (QualitySummary, error) {
+	res := QualitySummary{Participants: make([]ParticipantQuality, 0)}
+
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	f, err := os.Open(filepath.Join(dir, statsFile))
+	if os.IsNotExist(err) {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+	defer f.Close()
+
+	totals := map[string]*qualityTotals{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var sample StatsSample
+		if json.Unmarshal(scanner.Bytes(), &sample) != nil {
+			continue
+		}
+		res.Samples++
+		if res.From.IsZero() {
+			res.From = sample.Time
+		}
+		res.To = sample.Time
+
+		for _, p := range sample.Participants {
+			t, ok := totals[p.ID]
+			if !ok {
+				t = &qualityTotals{quality: ParticipantQuality{ID: p.ID}}
+				totals[p.ID] = t
+			}
+			q := &t.quality
+			if p.DisplayName != "" {
+				q.DisplayName = p.DisplayName
+			}
+			q.Samples++
+			t.received += p.PacketsReceived
+			t.lost += p.PacketsLost
+			t.concealed += p.ConcealedSamples
+			t.samples += p.SamplesReceived
+			t.jitter += p.JitterMs
+			t.bitrate += p.BitrateKbps
+			q.MaxPacketLoss = max(q.MaxPacketLoss, p.PacketLoss)
+			q.MaxJitterMs = max(q.MaxJitterMs, p.JitterMs)
+			if p.RttMs > 0 {
+				t.rtt += p.RttMs
+				t.rttCount++
+				q.MaxRttMs = max(q.MaxRttMs, p.RttMs)
+			}
+			if p.PacketLoss > poorPacketLoss || p.JitterMs > poorJitterMs || p.ConcealedPercent > poorConcealed {
+				t.poor++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return res, err
+	}
+
+	for _, t := range totals {
+		q := t.quality
+		n := float64(q.Samples)
+		if t.received+t.lost > 0 {
+			q.PacketLoss = float64(t.lost) * 100 / float64(t.received+t.lost)
+		}
+		if t.samples > 0 {
+			q.ConcealedPercent = float64(t.concealed) * 100 / float64(t.samples)
+		}
+		q.AvgJitterMs = t.jitter / n
+		q.AvgBitrateKbps = t.bitrate / n
+		if t.rttCount > 0 {
+			q.AvgRttMs = t.rtt / float64(t.rttCount)
+		}
+		q.PoorPercent = float64(t.poor) * 100 / n
+		res.Participants = append(res.Participants, q)
+	}
+	// Сначала участники с худшим качеством
+	sort.Slice(res.Participants, func(i, j int) bool {
+		a, b := res.Participants[i], res.Participants[j]
+		if a.PoorPercent != b.PoorPercent {
+			return a.PoorPercent > b.PoorPercent
+		}
+		return a.ID < b.ID
+	})
+	return res, nil
+}
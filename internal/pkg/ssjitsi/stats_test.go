@@ -0,0 +1,77 @@
+package ssjitsi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionQuality(t *testing.T) {
+	dir := t.TempDir()
+	if res, err := sessionQuality(dir); err != nil || res.Samples != 0 || len(res.Participants) != 0 {
+		t.Fatalf("sessionQuality() without stats = %+v, %v", res, err)
+	}
+
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	samples := []StatsSample{
+		{Time: t0, Participants: []ParticipantStats{
+			{ID: "alice", DisplayName: "Alice", PacketsReceived: 100, JitterMs: 10, BitrateKbps: 32, RttMs: 50, SamplesReceived: 1000},
+			// Джиттер выше порога
+			{ID: "bob", DisplayName: "Bob", PacketsReceived: 100, JitterMs: 40, BitrateKbps: 30, SamplesReceived: 1000},
+		}},
+		{Time: t0.Add(5 * time.Second), Participants: []ParticipantStats{
+			// Потери выше порога, имя в замере пропало
+			{ID: "alice", PacketsReceived: 50, PacketsLost: 6, PacketLoss: 10.71, JitterMs: 20, BitrateKbps: 28, SamplesReceived: 1000},
+			// Восстановлено 10% сэмплов
+			{ID: "bob", PacketsReceived: 100, JitterMs: 5, BitrateKbps: 30, ConcealedSamples: 100, SamplesReceived: 1000, ConcealedPercent: 10},
+			{ID: "dave", DisplayName: "Dave", PacketsReceived: 100, JitterMs: 1, BitrateKbps: 40, SamplesReceived: 1000},
+			{ID: "carol", DisplayName: "Carol", PacketsReceived: 100, JitterMs: 2, BitrateKbps: 20, RttMs: 30, SamplesReceived: 1000},
+		}},
+	}
+	for _, s := range samples {
+		if err := appendStats(dir, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.OpenFile(filepath.Join(dir, statsFile), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("not json\n")
+	f.Close()
+
+	res, err := sessionQuality(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Samples != 2 || !res.From.Equal(t0) || !res.To.Equal(t0.Add(5*time.Second)) {
+		t.Errorf("samples = %d, from %s, to %s", res.Samples, res.From, res.To)
+	}
+
+	// Сначала худшие по доле плохих интервалов, при равенстве - по ID
+	want := []ParticipantQuality{
+		{ID: "bob", DisplayName: "Bob", Samples: 2, AvgJitterMs: 22.5, MaxJitterMs: 40, AvgBitrateKbps: 30, ConcealedPercent: 5, PoorPercent: 100},
+		{ID: "alice", DisplayName: "Alice", Samples: 2, PacketLoss: 3.85, MaxPacketLoss: 10.71, AvgJitterMs: 15, MaxJitterMs: 20, AvgBitrateKbps: 30, AvgRttMs: 50, MaxRttMs: 50, PoorPercent: 50},
+		{ID: "carol", DisplayName: "Carol", Samples: 1, AvgJitterMs: 2, MaxJitterMs: 2, AvgBitrateKbps: 20, AvgRttMs: 30, MaxRttMs: 30},
+		{ID: "dave", DisplayName: "Dave", Samples: 1, AvgJitterMs: 1, MaxJitterMs: 1, AvgBitrateKbps: 40},
+	}
+	if len(res.Participants) != len(want) {
+		t.Fatalf("participants = %+v, want %d", res.Participants, len(want))
+	}
+	for i, w := range want {
+		t.Run(w.ID, func(t *testing.T) {
+			if got, exp := qualityString(res.Participants[i]), qualityString(w); got != exp {
+				t.Errorf("participant %d:\n got %s\nwant %s", i, got, exp)
+			}
+		})
+	}
+}
+
+// qualityString описывает сводку участника с округлением до сотых
+func qualityString(q ParticipantQuality) string {
+	return fmt.Sprintf("%s %q samples=%d loss=%.2f/%.2f jitter=%.2f/%.2f bitrate=%.2f concealed=%.2f rtt=%.2f/%.2f poor=%.2f",
+		q.ID, q.DisplayName, q.Samples, q.PacketLoss, q.MaxPacketLoss, q.AvgJitterMs, q.MaxJitterMs,
+		q.AvgBitrateKbps, q.ConcealedPercent, q.AvgRttMs, q.MaxRttMs, q.PoorPercent)
+}
@@ -0,0 +1,240 @@
+package ssjitsi
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Файл замеров громкости участников в каталоге сессии
+const activityFile = "activity.jsonl"
+
+// Параметры определения речи по уровню громкости
+const (
+	speechLevel     = 0.02                   // RMS, выше которого участник считается говорящим
+	speechHangover  = 600 * time.Millisecond // Паузы короче склеиваются в один интервал
+	speechMinLength = 300 * time.Millisecond // Более короткие интервалы - шум
+	monologueGap    = 2 * time.Second        // Паузы короче не прерывают реплику
+)
+
+// LevelSamples - замеры громкости дорожки за один чанк: V[i] снят в
+// момент T + i*Step (мс Unix)
+type LevelSamples struct {
+	T    int64     `json:"t"`
+	Step int64     `json:"step"`
+	V    []float64 `json:"v"`
+}
+
+// activityRecord - строка activity.jsonl
+type activityRecord struct {
+	UserID string `json:"userId"`
+	User   string `json:"user"`
+	LevelSamples
+}
+
+// SpeechInterval - интервал речи участника
+type SpeechInterval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ParticipantTalk - статистика речи одного участника
+type ParticipantTalk struct {
+	UserID         string           `json:"userId"`
+	User           string           `json:"user"`
+	TalkSec        float64          `json:"talkSec"`
+	Percent        float64          `json:"percent"`        // Доля от всего времени речи в сессии
+	SessionPercent float64          `json:"sessionPercent"` // Доля от длительности сессии
+	Turns          int              `json:"turns"`
+	LongestSec     float64          `json:"longestSec"`
+	Intervals      []SpeechInterval `json:"intervals,omitempty"`
+}
+
+// Monologue - самая длинная непрерывная реплика
+type Monologue struct {
+	UserID      string    `json:"userId"`
+	User        string    `json:"user"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	DurationSec float64   `json:"durationSec"`
+}
+
+// TalkTime - сводка по речи участников сессии
+type TalkTime struct {
+	Session          string            `json:"session"`
+	Start            time.Time         `json:"start"`
+	End              time.Time         `json:"end"`
+	DurationSec      float64           `json:"durationSec"`
+	TalkSec          float64           `json:"talkSec"`
+	Participants     []ParticipantTalk `json:"participants"`
+	LongestMonologue *Monologue        `json:"longestMonologue,omitempty"`
+}
+
+// Замеры разных дорожек одной сессии приходят параллельно
+var activityMu sync.Mutex
+
+// appendActivity дописывает замеры громкости из чанка в activity.jsonl
+func appendActivity(dir string, p Record) error {
+	data, err := json.Marshal(activityRecord{UserID: p.UserId, User: p.User, LevelSamples: *p.Levels})
+	if err != nil {
+		return err
+	}
+
+	activityMu.Lock()
+	defer activityMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(dir, activityFile), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// levelSample - один замер громкости
+type levelSample struct {
+	t     int64
+	step  int64
+	level float64
+}
+
+// sessionTalkTime строит шкалу речи участников по activity.jsonl и
+// считает сводку
+func sessionTalkTime(dir, sid string, withIntervals bool) (TalkTime, error) {
+	res := TalkTime{Session: sid, Participants: make([]ParticipantTalk, 0)}
+
+	f, err := os.Open(filepath.Join(dir, activityFile))
+	if os.IsNotExist(err) {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+	defer f.Close()
+
+	samples := map[string][]levelSample{}
+	names := map[string]string{}
+	var first, last int64
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var rec activityRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil || rec.Step <= 0 || len(rec.V) == 0 {
+			continue
+		}
+		if rec.User != "" {
+			names[rec.UserID] = rec.User
+		}
+		for i, v := range rec.V {
+			samples[rec.UserID] = append(samples[rec.UserID], levelSample{t: rec.T + int64(i)*rec.Step, step: rec.Step, level: v})
+		}
+		if first == 0 || rec.T < first {
+			first = rec.T
+		}
+		if end := rec.T + int64(len(rec.V))*rec.Step; end > last {
+			last = end
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return res, err
+	}
+	if first == 0 {
+		return res, nil
+	}
+	res.Start, res.End = time.UnixMilli(first), time.UnixMilli(last)
+	res.DurationSec = res.End.Sub(res.Start).Seconds()
+
+	for userID, list := range samples {
+		intervals := speechIntervals(list)
+		pt := ParticipantTalk{UserID: userID, User: names[userID]}
+		for _, in := range intervals {
+			pt.TalkSec += in.End.Sub(in.Start).Seconds()
+		}
+		for _, turn := range mergeIntervals(intervals, monologueGap) {
+			pt.Turns++
+			d := turn.End.Sub(turn.Start)
+			pt.LongestSec = math.Max(pt.LongestSec, d.Seconds())
+			if res.LongestMonologue == nil || d.Seconds() > res.LongestMonologue.DurationSec {
+				res.LongestMonologue = &Monologue{UserID: userID, User: pt.User, Start: turn.Start, End: turn.End, DurationSec: d.Seconds()}
+			}
+		}
+		if withIntervals {
+			pt.Intervals = intervals
+		}
+		res.TalkSec += pt.TalkSec
+		res.Participants = append(res.Participants, pt)
+	}
+
+	for i := range res.Participants {
+		pt := &res.Participants[i]
+		if res.TalkSec > 0 {
+			pt.Percent = round1(pt.TalkSec / res.TalkSec * 100)
+		}
+		if res.DurationSec > 0 {
+			pt.SessionPercent = round1(pt.TalkSec / res.DurationSec * 100)
+		}
+		pt.TalkSec = round1(pt.TalkSec)
+		pt.LongestSec = round1(pt.LongestSec)
+	}
+	res.TalkSec = round1(res.TalkSec)
+	if res.LongestMonologue != nil {
+		res.LongestMonologue.DurationSec = round1(res.LongestMonologue.DurationSec)
+	}
+	sort.Slice(res.Participants, func(i, j int) bool {
+		return res.Participants[i].TalkSec > res.Participants[j].TalkSec
+	})
+	return res, nil
+}
+
+// speechIntervals превращает замеры в интервалы речи: короткие паузы
+// склеиваются, короткие всплески отбрасываются
+func speechIntervals(samples []levelSample) []SpeechInterval {
+	sort.Slice(samples, func(i, j int) bool { return samples[i].t < samples[j].t })
+
+	var raw []SpeechInterval
+	for _, s := range samples {
+		if s.level < speechLevel {
+			continue
+		}
+		start, end := time.UnixMilli(s.t), time.UnixMilli(s.t+s.step)
+		if n := len(raw); n > 0 && !start.After(raw[n-1].End) {
+			if end.After(raw[n-1].End) {
+				raw[n-1].End = end
+			}
+			continue
+		}
+		raw = append(raw, SpeechInterval{Start: start, End: end})
+	}
+
+	intervals := make([]SpeechInterval, 0, len(raw))
+	for _, in := range mergeIntervals(raw, speechHangover) {
+		if in.End.Sub(in.Start) >= speechMinLength {
+			intervals = append(intervals, in)
+		}
+	}
+	return intervals
+}
+
+// mergeIntervals склеивает упорядоченные интервалы с паузами не длиннее gap
+func mergeIntervals(intervals []SpeechInterval, gap time.Duration) []SpeechInterval {
+	var res []SpeechInterval
+	for _, in := range intervals {
+		if n := len(res); n > 0 && in.Start.Sub(res[n-1].End) <= gap {
+			if in.End.After(res[n-1].End) {
+				res[n-1].End = in.End
+			}
+			continue
+		}
+		res = append(res, in)
+	}
+	return res
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
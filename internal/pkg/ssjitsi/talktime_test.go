@@ -0,0 +1,155 @@
+package ssjitsi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Начало шкалы в тестах речи
+const talkT0 = int64(1_700_000_000_000)
+
+// levels повторяет уровень n раз
+func levels(level float64, n int) []float64 {
+	v := make([]float64, n)
+	for i := range v {
+		v[i] = level
+	}
+	return v
+}
+
+// series собирает значения уровней подряд
+func series(parts ...[]float64) []float64 {
+	var v []float64
+	for _, p := range parts {
+		v = append(v, p...)
+	}
+	return v
+}
+
+// spans описывает интервалы в миллисекундах относительно talkT0
+func spans(intervals []SpeechInterval) string {
+	parts := make([]string, 0, len(intervals))
+	for _, in := range intervals {
+		parts = append(parts, fmt.Sprintf("%d-%d", in.Start.UnixMilli()-talkT0, in.End.UnixMilli()-talkT0))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestSpeechIntervals(t *testing.T) {
+	loud, quiet := 0.1, 0.0
+	tests := []struct {
+		name    string
+		values  []float64 // Замеры с шагом 100 мс от talkT0
+		reverse bool
+		want    string
+	}{
+		{"silence", levels(quiet, 20), false, ""},
+		{"short burst is noise", series(levels(loud, 2), levels(quiet, 5)), false, ""},
+		{"single interval", series(levels(loud, 5), levels(quiet, 5)), false, "0-500"},
+		{"short pause is merged", series(levels(loud, 5), levels(quiet, 5), levels(loud, 5)), false, "0-1500"},
+		{"long pause splits", series(levels(loud, 5), levels(quiet, 7), levels(loud, 5)), false, "0-500 1200-1700"},
+		{"threshold counts as speech", levels(speechLevel, 4), false, "0-400"},
+		{"below threshold is silence", levels(speechLevel-0.001, 4), false, ""},
+		{"unordered samples", series(levels(loud, 5), levels(quiet, 7), levels(loud, 5)), true, "0-500 1200-1700"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := make([]levelSample, 0, len(tt.values))
+			for i, v := range tt.values {
+				samples = append(samples, levelSample{t: talkT0 + int64(i)*100, step: 100, level: v})
+			}
+			if tt.reverse {
+				for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
+					samples[i], samples[j] = samples[j], samples[i]
+				}
+			}
+			if got := spans(speechIntervals(samples)); got != tt.want {
+				t.Errorf("speechIntervals() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionTalkTime(t *testing.T) {
+	dir := t.TempDir()
+	if res, err := sessionTalkTime(dir, "s1", false); err != nil || len(res.Participants) != 0 || res.LongestMonologue != nil {
+		t.Fatalf("sessionTalkTime() without activity = %+v, %v", res, err)
+	}
+
+	loud, quiet := 0.1, 0.0
+	records := []Record{
+		// Alice: 0-1s и 2-3s - одна реплика с паузой в секунду, затем 6-7s
+		{UserId: "alice", User: "Alice", Levels: &LevelSamples{T: talkT0, Step: 100, V: series(levels(loud, 10), levels(quiet, 10), levels(loud, 10))}},
+		{UserId: "alice", Levels: &LevelSamples{T: talkT0 + 6000, Step: 100, V: levels(loud, 10)}},
+		// Bob: 3-5.5s
+		{UserId: "bob", User: "Bob", Levels: &LevelSamples{T: talkT0 + 3000, Step: 100, V: series(levels(loud, 25), levels(quiet, 5))}},
+		// Без шага замеры не учитываются
+		{UserId: "carol", User: "Carol", Levels: &LevelSamples{T: talkT0, V: levels(loud, 100)}},
+	}
+	for _, p := range records {
+		if err := appendActivity(dir, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.OpenFile(filepath.Join(dir, activityFile), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("not json\n")
+	f.Close()
+
+	res, err := sessionTalkTime(dir, "s1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Session != "s1" || res.DurationSec != 7 || res.TalkSec != 5.5 {
+		t.Errorf("session = %q, duration %v, talk %v; want s1, 7, 5.5", res.Session, res.DurationSec, res.TalkSec)
+	}
+	if !res.Start.Equal(time.UnixMilli(talkT0)) || !res.End.Equal(time.UnixMilli(talkT0+7000)) {
+		t.Errorf("session bounds %s - %s", res.Start, res.End)
+	}
+
+	want := []struct {
+		userID, user   string
+		talk, longest  float64
+		percent, share float64
+		turns          int
+		intervals      string
+	}{
+		{"alice", "Alice", 3, 3, 54.5, 42.9, 2, "0-1000 2000-3000 6000-7000"},
+		{"bob", "Bob", 2.5, 2.5, 45.5, 35.7, 1, "3000-5500"},
+	}
+	if len(res.Participants) != len(want) {
+		t.Fatalf("participants = %+v, want %d", res.Participants, len(want))
+	}
+	for i, w := range want {
+		p := res.Participants[i]
+		got := fmt.Sprint(p.UserID, p.User, p.TalkSec, p.LongestSec, p.Percent, p.SessionPercent, p.Turns, spans(p.Intervals))
+		if exp := fmt.Sprint(w.userID, w.user, w.talk, w.longest, w.percent, w.share, w.turns, w.intervals); got != exp {
+			t.Errorf("participant %d = %s, want %s", i, got, exp)
+		}
+	}
+
+	m := res.LongestMonologue
+	if m == nil {
+		t.Fatal("longest monologue is missing")
+	}
+	if m.UserID != "alice" || m.User != "Alice" || m.DurationSec != 3 || m.Start.UnixMilli() != talkT0 || m.End.UnixMilli() != talkT0+3000 {
+		t.Errorf("longest monologue = %+v, want alice 0-3s", *m)
+	}
+
+	// Без withIntervals интервалы не отдаются
+	res, err = sessionTalkTime(dir, "s1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range res.Participants {
+		if p.Intervals != nil {
+			t.Errorf("participant %s has intervals without withIntervals", p.UserID)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package ssjitsi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// Каталог снимков внутри каталога сессии
+const screenshotsDir = "screenshots"
+
+// Параметры снимков по умолчанию
+const (
+	defaultTimelapseFormat  = "jpeg"
+	defaultTimelapseQuality = 80
+	defaultTimelapseKeepAll = 10 * time.Minute
+	defaultTimelapseThin    = time.Minute
+)
+
+// TimelapseOptions включает периодические снимки страницы встречи в каталог
+// сессии. Свежие снимки хранятся все, более старые прореживаются.
+type TimelapseOptions struct {
+	Interval     time.Duration `yaml:"Interval"`     // Период снимков; 0 - выключено
+	FullPage     bool          `yaml:"FullPage"`     // Вся страница вместо видимой области
+	Format       string        `yaml:"Format"`       // jpeg (по умолчанию) или png
+	Quality      int           `yaml:"Quality"`      // Качество JPEG 1-100 (по умолчанию 80)
+	KeepAll      time.Duration `yaml:"KeepAll"`      // Сколько хранить все снимки (по умолчанию 10m)
+	ThinInterval time.Duration `yaml:"ThinInterval"` // Старше KeepAll остается один снимок на интервал (по умолчанию 1m)
+	MaxAge       time.Duration `yaml:"MaxAge"`       // Удалять снимки старше; 0 - не удалять
+}
+
+// Screenshot описывает сохраненный снимок сессии
+type Screenshot struct {
+	Name string    `json:"name"` // Путь относительно каталога сессии
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// validate проверяет параметры снимков при загрузке конфигурации
+func (o TimelapseOptions) validate() error {
+	switch o.Format {
+	case "", "jpeg", "png":
+	default:
+		return fmt.Errorf("Timelapse.Format must be jpeg or png, got %q", o.Format)
+	}
+	if o.Quality < 0 || o.Quality > 100 {
+		return fmt.Errorf("Timelapse.Quality must be between 1 and 100, got %d", o.Quality)
+	}
+	if o.Interval > 0 && o.Interval < time.Second {
+		return fmt.Errorf("Timelapse.Interval %s is too small", o.Interval)
+	}
+	if o.ThinInterval > 0 && o.ThinInterval < time.Second {
+		return fmt.Errorf("Timelapse.ThinInterval %s is too small", o.ThinInterval)
+	}
+	return nil
+}
+
+// format возвращает формат снимков с учетом значения по умолчанию
+func (o TimelapseOptions) format() string {
+	if o.Format == "" {
+		return defaultTimelapseFormat
+	}
+	return o.Format
+}
+
+// extension возвращает расширение файлов снимков
+func (o TimelapseOptions) extension() string {
+	if o.format() == "png" {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// capture делает один снимок вкладки
+func (o TimelapseOptions) capture(ctx context.Context) ([]byte, error) {
+	var buf []byte
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		params := page.CaptureScreenshot().WithFromSurface(true)
+		if o.format() == "png" {
+			params = params.WithFormat(page.CaptureScreenshotFormatPng)
+		} else {
+			quality := o.Quality
+			if quality == 0 {
+				quality = defaultTimelapseQuality
+			}
+			params = params.WithFormat(page.CaptureScreenshotFormatJpeg).WithQuality(int64(quality))
+		}
+		if o.FullPage {
+			_, _, _, _, _, content, err := page.GetLayoutMetrics().Do(ctx)
+			if err != nil {
+				return err
+			}
+			params = params.WithCaptureBeyondViewport(true).WithClip(&page.Viewport{
+				Width:  math.Ceil(content.Width),
+				Height: math.Ceil(content.Height),
+				Scale:  1,
+			})
+		}
+		var err error
+		buf, err = params.Do(ctx)
+		return err
+	}))
+	return buf, err
+}
+
+// runTimelapse делает снимки, пока открыта вкладка бота. Снимки во время
+// перезапуска или восстановления после перезагрузки пропускаются.
+func (bot *Bot) runTimelapse(ctx context.Context) {
+	opts := bot.Timelapse
+	if opts.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if bot.GetStatus() != StatusRunning {
+				continue
+			}
+			dir := filepath.Join(bot.SessionDir(), screenshotsDir)
+			if err := bot.takeTimelapseShot(ctx, dir, now); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Бот %s: не удалось сделать снимок: %v", bot.ID, err)
+				}
+				continue
+			}
+			thinScreenshots(dir, opts, now)
+		}
+	}
+}
+
+// takeTimelapseShot сохраняет снимок в файл с временем съемки в имени
+func (bot *Bot) takeTimelapseShot(ctx context.Context, dir string, now time.Time) error {
+	buf, err := bot.Timelapse.capture(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := strconv.FormatInt(now.UnixMilli(), 10) + bot.Timelapse.extension()
+	return os.WriteFile(filepath.Join(dir, name), buf, 0644)
+}
+
+// listScreenshots возвращает снимки сессии по возрастанию времени
+func listScreenshots(sessionDir string) []Screenshot {
+	shots := make([]Screenshot, 0)
+	entries, err := os.ReadDir(filepath.Join(sessionDir, screenshotsDir))
+	if err != nil {
+		return shots
+	}
+	for _, entry := range entries {
+		ms, err := strconv.ParseInt(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())), 10, 64)
+		if entry.IsDir() || err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		shots = append(shots, Screenshot{
+			Name: screenshotsDir + "/" + entry.Name(),
+			Time: time.UnixMilli(ms),
+			Size: info.Size(),
+		})
+	}
+	sort.Slice(shots, func(i, j int) bool {
+		return shots[i].Time.Before(shots[j].Time)
+	})
+	return shots
+}
+
+// thinScreenshots удаляет лишние снимки: моложе KeepAll остаются все,
+// старше - первый снимок в каждом интервале ThinInterval, старше MaxAge -
+// ни одного
+func thinScreenshots(dir string, opts TimelapseOptions, now time.Time) {
+	keepAll, thin := opts.KeepAll, opts.ThinInterval
+	if keepAll <= 0 {
+		keepAll = defaultTimelapseKeepAll
+	}
+	if thin <= 0 {
+		thin = defaultTimelapseThin
+	}
+
+	kept := map[int64]bool{}
+	for _, shot := range listScreenshots(filepath.Dir(dir)) {
+		age := now.Sub(shot.Time)
+		if age < keepAll {
+			continue
+		}
+		bucket := shot.Time.UnixMilli() / thin.Milliseconds()
+		if (opts.MaxAge <= 0 || age < opts.MaxAge) && !kept[bucket] {
+			kept[bucket] = true
+			continue
+		}
+		os.Remove(filepath.Join(filepath.Dir(dir), filepath.FromSlash(shot.Name)))
+	}
+}
@@ -0,0 +1,75 @@
+package ssjitsi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestThinScreenshots(t *testing.T) {
+	// Момент, кратный пяти минутам, чтобы границы интервалов были предсказуемы
+	now := time.UnixMilli(1_700_000_100_000)
+	shots := []int{-780, -770, -750, -720, -700, -630, -600, -590, -30, 0}
+
+	tests := []struct {
+		name string
+		opts TimelapseOptions
+		want []int // Оставшиеся снимки, секунды относительно now
+	}{
+		{
+			name: "defaults keep one shot per minute after 10m",
+			opts: TimelapseOptions{},
+			want: []int{-780, -720, -630, -600, -590, -30, 0},
+		},
+		{
+			name: "max age removes old shots",
+			opts: TimelapseOptions{MaxAge: 11 * time.Minute},
+			want: []int{-630, -600, -590, -30, 0},
+		},
+		{
+			name: "custom keep all and thin interval",
+			opts: TimelapseOptions{KeepAll: time.Minute, ThinInterval: 5 * time.Minute},
+			want: []int{-780, -600, -30, 0},
+		},
+		{
+			name: "keep all covers every shot",
+			opts: TimelapseOptions{KeepAll: time.Hour},
+			want: shots,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := t.TempDir()
+			dir := filepath.Join(session, screenshotsDir)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range shots {
+				name := strconv.FormatInt(now.Add(time.Duration(s)*time.Second).UnixMilli(), 10) + ".jpg"
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Посторонние файлы не трогаем
+			if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			thinScreenshots(dir, tt.opts, now)
+
+			got := make([]int, 0)
+			for _, shot := range listScreenshots(session) {
+				got = append(got, int(shot.Time.Sub(now)/time.Second))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("remaining shots %v, want %v", got, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+				t.Errorf("unrelated file removed: %v", err)
+			}
+		})
+	}
+}
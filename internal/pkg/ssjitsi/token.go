@@ -0,0 +1,79 @@
+package ssjitsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// meetingToken возвращает токен для входа во встречу и время его истечения
+func (bot *Bot) meetingToken() (string, time.Time, error) {
+	switch getAuthMethod(bot) {
+	case "TokenService":
+		return bot.TokenService.Token(bot, false)
+	case "JWT":
+		token, err := bot.jwtToken()
+		return token, time.Now().Add(bot.JWT.ttl()), err
+	}
+	return "", time.Time{}, errors.New("bot has no token authentication")
+}
+
+// tokenRefreshAt возвращает момент, когда токен нужно обновить
+func (bot *Bot) tokenRefreshAt(expiresAt time.Time) time.Time {
+	if getAuthMethod(bot) == "TokenService" {
+		return expiresAt.Add(-bot.TokenService.refreshBefore())
+	}
+	// Обновляем JWT, когда прошло 3/4 времени жизни
+	return expiresAt.Add(-bot.JWT.ttl() / 4)
+}
+
+// refreshToken периодически получает новый токен и передает его странице,
+// чтобы переподключение или перезагрузка во время долгой встречи не падали
+// на просроченном токене.
+func (bot *Bot) refreshToken(ctx context.Context, expiresAt time.Time) {
+	for {
+		wait := time.Until(bot.tokenRefreshAt(expiresAt))
+		if wait < time.Minute {
+			wait = time.Minute
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		token, exp, err := bot.meetingToken()
+		if err != nil {
+			log.Printf("Бот %s: ошибка обновления токена: %v", bot.ID, err)
+			bot.Events.Add("error", "token refresh: "+err.Error())
+			continue
+		}
+		expiresAt = exp
+
+		tokenJSON, _ := json.Marshal(token)
+		// SET_JWT обновляет токен в состоянии приложения для переподключения,
+		// replaceState - в адресе страницы на случай перезагрузки
+		script := fmt.Sprintf(`(function(jwt) {
+			if (window.APP && APP.store) {
+				APP.store.dispatch({ type: 'SET_JWT', jwt: jwt });
+			}
+			const u = new URL(window.location.href);
+			u.searchParams.set('jwt', jwt);
+			window.history.replaceState(window.history.state, '', u.toString());
+			return true;
+		})(%s)`, tokenJSON)
+
+		var ok bool
+		if err := chromedp.Run(ctx, chromedp.Evaluate(script, &ok)); err != nil {
+			log.Printf("Бот %s: не удалось передать новый токен странице: %v", bot.ID, err)
+			continue
+		}
+		bot.Events.Add("info", "token refreshed")
+		log.Printf("Бот %s: токен обновлен", bot.ID)
+	}
+}
@@ -0,0 +1,219 @@
+package ssjitsi
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultTokenServiceTimeout = 15 * time.Second
+	defaultTokenRefreshBefore  = 5 * time.Minute
+)
+
+// TokenServiceOptions описывает внешний сервис выдачи токенов встречи
+type TokenServiceOptions struct {
+	URL             string            `yaml:"URL"`             // Адрес сервиса; запрос POST с JSON телом
+	BearerToken     string            `yaml:"BearerToken"`     // Токен для заголовка Authorization: Bearer
+	BearerTokenFile string            `yaml:"BearerTokenFile"` // Файл с bearer токеном
+	CertFile        string            `yaml:"CertFile"`        // Клиентский сертификат для mTLS
+	KeyFile         string            `yaml:"KeyFile"`         // Закрытый ключ клиентского сертификата
+	CAFile          string            `yaml:"CAFile"`          // CA для проверки сертификата сервиса
+	Headers         map[string]string `yaml:"Headers"`         // Дополнительные заголовки запроса
+	Timeout         time.Duration     `yaml:"Timeout"`         // Таймаут запроса (по умолчанию 15s)
+	RefreshBefore   time.Duration     `yaml:"RefreshBefore"`   // За сколько до истечения обновлять токен (по умолчанию 5m)
+
+	mu        sync.Mutex
+	client    *http.Client
+	token     string
+	expiresAt time.Time
+}
+
+// tokenServiceRequest - тело запроса к сервису токенов
+type tokenServiceRequest struct {
+	Room    string `json:"room"`
+	BotName string `json:"botName"`
+	BotID   string `json:"botId"`
+	Server  string `json:"server"`
+}
+
+// tokenServiceResponse - ответ сервиса токенов. Срок действия берется из
+// expiresAt (RFC3339 или unix), expiresIn (секунды) или claim exp токена.
+type tokenServiceResponse struct {
+	Token     string          `json:"token"`
+	JWT       string          `json:"jwt"`
+	ExpiresAt json.RawMessage `json:"expiresAt"`
+	ExpiresIn int64           `json:"expiresIn"`
+}
+
+func (o *TokenServiceOptions) refreshBefore() time.Duration {
+	if o.RefreshBefore > 0 {
+		return o.RefreshBefore
+	}
+	return defaultTokenRefreshBefore
+}
+
+// Token возвращает закэшированный токен или запрашивает новый, если старый
+// скоро истечет
+func (o *TokenServiceOptions) Token(bot *Bot, force bool) (string, time.Time, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !force && o.token != "" && time.Now().Before(o.expiresAt.Add(-o.refreshBefore())) {
+		return o.token, o.expiresAt, nil
+	}
+
+	token, expiresAt, err := o.fetch(bot)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	o.token, o.expiresAt = token, expiresAt
+	return token, expiresAt, nil
+}
+
+func (o *TokenServiceOptions) fetch(bot *Bot) (string, time.Time, error) {
+	client, err := o.httpClient()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	body, _ := json.Marshal(tokenServiceRequest{
+		Room:    bot.Room,
+		BotName: bot.BotName,
+		BotID:   bot.ID,
+		Server:  bot.JitsiServer,
+	})
+	req, err := http.NewRequest(http.MethodPost, o.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range o.Headers {
+		req.Header.Set(k, v)
+	}
+
+	bearer := o.BearerToken
+	if o.BearerTokenFile != "" {
+		data, err := os.ReadFile(o.BearerTokenFile)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to read bearer token: %v", err)
+		}
+		bearer = strings.TrimSpace(string(data))
+	}
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("token service request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("token service returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var res tokenServiceResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid token service response: %v", err)
+	}
+	token := res.Token
+	if token == "" {
+		token = res.JWT
+	}
+	if token == "" {
+		return "", time.Time{}, errors.New("token service response has no token")
+	}
+
+	expiresAt, err := res.expiry(token)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+func (r tokenServiceResponse) expiry(token string) (time.Time, error) {
+	if len(r.ExpiresAt) > 0 && string(r.ExpiresAt) != "null" {
+		var s string
+		if json.Unmarshal(r.ExpiresAt, &s) == nil {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return t, nil
+			}
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return time.Unix(n, 0), nil
+			}
+			return time.Time{}, fmt.Errorf("invalid expiresAt %q", s)
+		}
+		var n int64
+		if err := json.Unmarshal(r.ExpiresAt, &n); err != nil {
+			return time.Time{}, fmt.Errorf("invalid expiresAt: %v", err)
+		}
+		return time.Unix(n, 0), nil
+	}
+	if r.ExpiresIn > 0 {
+		return time.Now().Add(time.Duration(r.ExpiresIn) * time.Second), nil
+	}
+
+	// Подпись не проверяем: ключа у нас нет, нужен только exp
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return time.Time{}, fmt.Errorf("token has no expiry and cannot be parsed: %v", err)
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return time.Time{}, errors.New("token service response has no expiry")
+	}
+	return exp.Time, nil
+}
+
+func (o *TokenServiceOptions) httpClient() (*http.Client, error) {
+	if o.client != nil {
+		return o.client, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if o.CAFile != "" {
+		ca, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("no certificates found in CA file")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = defaultTokenServiceTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	o.client = &http.Client{Timeout: timeout, Transport: transport}
+	return o.client, nil
+}
@@ -0,0 +1,132 @@
+package ssjitsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestTokenServiceExpiry(t *testing.T) {
+	exp := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	withExp, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": exp.Unix()}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutExp, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"room": "*"}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		token   string
+		want    time.Time
+		in      time.Duration // Для expiresIn: ожидаемое смещение от текущего времени
+		wantErr bool
+	}{
+		{"expiresAt RFC3339", `{"expiresAt": "2030-01-02T03:04:05Z"}`, "opaque", exp, 0, false},
+		{"expiresAt RFC3339 with offset", `{"expiresAt": "2030-01-02T06:04:05+03:00"}`, "opaque", exp, 0, false},
+		{"expiresAt unix number", fmt.Sprintf(`{"expiresAt": %d}`, exp.Unix()), "opaque", exp, 0, false},
+		{"expiresAt unix string", fmt.Sprintf(`{"expiresAt": "%d"}`, exp.Unix()), "opaque", exp, 0, false},
+		{"expiresAt wins over expiresIn", `{"expiresAt": "2030-01-02T03:04:05Z", "expiresIn": 60}`, withoutExp, exp, 0, false},
+		{"expiresIn", `{"expiresIn": 600}`, "opaque", time.Time{}, 10 * time.Minute, false},
+		{"null expiresAt falls back to expiresIn", `{"expiresAt": null, "expiresIn": 600}`, "opaque", time.Time{}, 10 * time.Minute, false},
+		{"JWT exp", `{}`, withExp, exp, 0, false},
+		{"invalid expiresAt", `{"expiresAt": "tomorrow"}`, withExp, time.Time{}, 0, true},
+		{"invalid expiresAt type", `{"expiresAt": true}`, withExp, time.Time{}, 0, true},
+		{"JWT without exp", `{}`, withoutExp, time.Time{}, 0, true},
+		{"opaque token without expiry", `{}`, "opaque", time.Time{}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var res tokenServiceResponse
+			if err := json.Unmarshal([]byte(tt.body), &res); err != nil {
+				t.Fatal(err)
+			}
+			before := time.Now()
+			got, err := res.expiry(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expiry() = %s, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.in > 0 {
+				if got.Before(before.Add(tt.in)) || got.After(time.Now().Add(tt.in)) {
+					t.Errorf("expiry() = %s, want now + %s", got, tt.in)
+				}
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("expiry() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenServiceToken(t *testing.T) {
+	var calls int
+	var last tokenServiceRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if got := r.Header.Get("Authorization"); got != "Bearer bearer" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("X-Tenant"); got != "acme" {
+			t.Errorf("X-Tenant = %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&last); err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jwt":       fmt.Sprintf("token%d", calls),
+			"expiresIn": 3600,
+		})
+	}))
+	defer srv.Close()
+
+	o := &TokenServiceOptions{URL: srv.URL, BearerToken: "bearer", Headers: map[string]string{"X-Tenant": "acme"}}
+	bot := &Bot{ID: "b1", BotName: "Recorder", Room: "daily", JitsiServer: "https://meet.example.com"}
+
+	tests := []struct {
+		name  string
+		force bool
+		want  string
+		calls int
+	}{
+		{"first request", false, "token1", 1},
+		{"cached", false, "token1", 1},
+		{"forced refresh", true, "token2", 2},
+		{"cached after refresh", false, "token2", 2},
+	}
+	for _, tt := range tests {
+		token, expiresAt, err := o.Token(bot, tt.force)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if token != tt.want || calls != tt.calls {
+			t.Errorf("%s: token %q after %d calls, want %q after %d", tt.name, token, calls, tt.want, tt.calls)
+		}
+		if time.Until(expiresAt) < 59*time.Minute {
+			t.Errorf("%s: expiresAt = %s", tt.name, expiresAt)
+		}
+	}
+	if last != (tokenServiceRequest{Room: "daily", BotName: "Recorder", BotID: "b1", Server: "https://meet.example.com"}) {
+		t.Errorf("request body = %+v", last)
+	}
+
+	// Токен, который истечет раньше RefreshBefore, запрашивается заново
+	o.expiresAt = time.Now().Add(time.Minute)
+	if token, _, err := o.Token(bot, false); err != nil || token != "token3" {
+		t.Errorf("Token() near expiry = %q, %v; want token3", token, err)
+	}
+}
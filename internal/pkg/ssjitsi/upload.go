@@ -0,0 +1,246 @@
+package ssjitsi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Адрес приемника чанков по умолчанию: только loopback, порт выбирается системой
+const defaultUploadListen = "127.0.0.1:0"
+
+// Максимальный размер одного чанка
+const maxChunkSize = 64 << 20
+
+// Заголовки с метаданными чанка
+const (
+	headerTrack  = "X-Ssbot-Track"   // id audio элемента
+	headerUserID = "X-Ssbot-User-Id" // id участника
+	headerUser   = "X-Ssbot-User"    // Отображаемое имя (encodeURIComponent)
+	headerRoom   = "X-Ssbot-Room"    // Комната (encodeURIComponent)
+	headerMyID   = "X-Ssbot-My-Id"   // id бота в конференции
+	headerKind   = "X-Ssbot-Kind"    // audio или video
+	headerSource = "X-Ssbot-Source"  // microphone, camera, desktop или mix
+	headerLevels = "X-Ssbot-Levels"  // Замеры громкости за время чанка (JSON)
+)
+
+// UploadOptions настраивает передачу чанков записи по HTTP вместо привязки CDP
+type UploadOptions struct {
+	Disabled bool   `yaml:"Disabled"` // Передавать чанки только через привязку CDP (base64)
+	Listen   string `yaml:"Listen"`   // Адрес приемника (по умолчанию 127.0.0.1 со случайным портом)
+}
+
+// ChunkServer принимает сырые чанки записи от страниц ботов. Каждый запуск
+// бота получает собственный токен.
+type ChunkServer struct {
+	opts UploadOptions
+
+	once     sync.Once
+	startErr error
+	url      string
+
+	mu     sync.RWMutex
+	tokens map[string]*Bot
+}
+
+// NewChunkServer создает приемник; он запускается при первом обращении
+func NewChunkServer(opts UploadOptions) *ChunkServer {
+	return &ChunkServer{opts: opts, tokens: map[string]*Bot{}}
+}
+
+// start запускает HTTP приемник один раз
+func (s *ChunkServer) start() error {
+	s.once.Do(func() {
+		addr := s.opts.Listen
+		if addr == "" {
+			addr = defaultUploadListen
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			s.startErr = fmt.Errorf("failed to start chunk upload listener: %v", err)
+			return
+		}
+
+		router := gin.New()
+		router.Use(gin.Recovery(), uploadCORSMiddleware())
+		router.POST("/chunks", s.upload)
+
+		s.url = "http://" + ln.Addr().String() + "/chunks"
+		log.Printf("Приемник чанков записи: %s", s.url)
+		go func() {
+			if err := http.Serve(ln, router); err != nil {
+				log.Printf("Приемник чанков остановлен: %v", err)
+			}
+		}()
+	})
+	return s.startErr
+}
+
+// register выдает боту новый токен загрузки взамен старого
+func (s *ChunkServer) register(bot *Bot) (string, error) {
+	if err := s.start(); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t, b := range s.tokens {
+		if b == bot {
+			delete(s.tokens, t)
+		}
+	}
+	s.tokens[token] = bot
+	return token, nil
+}
+
+// unregister отзывает токен бота
+func (s *ChunkServer) unregister(bot *Bot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t, b := range s.tokens {
+		if b == bot {
+			delete(s.tokens, t)
+		}
+	}
+}
+
+// upload принимает один чанк: тело - сырые байты, метаданные в заголовках
+func (s *ChunkServer) upload(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	s.mu.RLock()
+	bot := s.tokens[token]
+	s.mu.RUnlock()
+	if token == "" || bot == nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	p := Record{
+		U:      c.GetHeader(headerTrack),
+		UserId: c.GetHeader(headerUserID),
+		User:   unescapeHeader(c.GetHeader(headerUser)),
+		Room:   unescapeHeader(c.GetHeader(headerRoom)),
+		Myid:   c.GetHeader(headerMyID),
+		Kind:   c.GetHeader(headerKind),
+		Source: c.GetHeader(headerSource),
+	}
+	if p.U == "" || p.UserId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "track and user id headers are required"})
+		return
+	}
+	if levels := c.GetHeader(headerLevels); levels != "" {
+		p.Levels = &LevelSamples{}
+		if err := json.Unmarshal([]byte(levels), p.Levels); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid levels header"})
+			return
+		}
+	}
+
+	// Тело пишется в файл потоком. Если оно оборвется, writeTrackChunk
+	// обрежет файл до прежнего размера, а страница повторит чанк через привязку.
+	body := &chunkBody{r: http.MaxBytesReader(c.Writer, c.Request.Body, maxChunkSize), left: c.Request.ContentLength}
+	if err := bot.writeTrackChunk(p, body); err != nil {
+		if body.err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to read chunk: %v", body.err)})
+			return
+		}
+		log.Printf("Бот %s: ошибка записи чанка: %v", bot.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// chunkBody читает тело чанка и запоминает ошибку чтения, чтобы отличить
+// оборванный запрос от ошибки записи файла. Тело короче Content-Length
+// считается оборванным.
+type chunkBody struct {
+	r    io.Reader
+	left int64 // Сколько байт еще ожидается; -1, если длина неизвестна
+	err  error
+}
+
+func (b *chunkBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if b.left >= 0 {
+		b.left -= int64(n)
+		if err == io.EOF && b.left > 0 {
+			err = errors.New("chunk is shorter than Content-Length")
+		}
+	}
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// unescapeHeader декодирует значение, закодированное encodeURIComponent
+func unescapeHeader(v string) string {
+	if s, err := url.PathUnescape(v); err == nil {
+		return s
+	}
+	return v
+}
+
+// uploadCORSMiddleware разрешает запросы со страницы встречи. Страница
+// открыта с публичного адреса, поэтому Chrome перед запросом к loopback
+// отправляет preflight Private Network Access.
+func uploadCORSMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			origin = "*"
+		}
+		h := c.Writer.Header()
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Vary", "Origin")
+		h.Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		h.Set("Access-Control-Allow-Headers", strings.Join([]string{
+			"Authorization", "Content-Type", headerTrack, headerUserID, headerUser, headerRoom, headerMyID,
+			headerKind, headerSource, headerLevels,
+		}, ", "))
+		h.Set("Access-Control-Max-Age", "600")
+		if c.GetHeader("Access-Control-Request-Private-Network") == "true" {
+			h.Set("Access-Control-Allow-Private-Network", "true")
+		}
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// addUploadConfig передает странице адрес приемника и токен. Без них
+// скрипт записи отправляет чанки через привязку CDP.
+func (bot *Bot) addUploadConfig(cfg map[string]interface{}) error {
+	// Удаленный браузер не может обратиться к loopback адресу сервера
+	if bot.uploader == nil || bot.Chrome.RemoteURL != "" || (bot.pool != nil && bot.pool.chrome.RemoteURL != "") {
+		return nil
+	}
+	token, err := bot.uploader.register(bot)
+	if err != nil {
+		return err
+	}
+	cfg["uploadUrl"] = bot.uploader.url
+	cfg["uploadToken"] = token
+	return nil
+}
@@ -0,0 +1,209 @@
+package ssjitsi
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Размер чанка в бенчмарках: примерно секунда видео или несколько секунд аудио
+const benchChunkSize = 64 << 10
+
+// bindingEvent повторяет событие Runtime.bindingCalled, в котором CDP
+// доставляет полезную нагрузку привязки
+type bindingEvent struct {
+	Method string `json:"method"`
+	Params struct {
+		Name               string `json:"name"`
+		Payload            string `json:"payload"`
+		ExecutionContextID int64  `json:"executionContextId"`
+	} `json:"params"`
+}
+
+// bindingMessage готовит сообщение CDP так же, как его собирает страница:
+// data URL, base64, JSON полезной нагрузки и JSON события
+func bindingMessage(b testing.TB, track string, chunk []byte) []byte {
+	dataURL := "data:audio/webm;base64," + base64.StdEncoding.EncodeToString(chunk)
+	payload, err := json.Marshal(Record{
+		U:      track,
+		D:      strings.SplitN(dataURL, ",", 2)[1],
+		User:   track,
+		UserId: track,
+		Room:   "bench",
+		Myid:   "bot",
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	var ev bindingEvent
+	ev.Method = "Runtime.bindingCalled"
+	ev.Params.Name = "ssbot_writeSound"
+	ev.Params.Payload = string(payload)
+	ev.Params.ExecutionContextID = 1
+	msg, err := json.Marshal(ev)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return msg
+}
+
+// receiveBinding разбирает сообщение CDP и пишет чанк, как обработчик привязки
+func receiveBinding(bot *Bot, msg []byte) error {
+	var ev bindingEvent
+	if err := json.Unmarshal(msg, &ev); err != nil {
+		return err
+	}
+	var p Record
+	if err := json.Unmarshal([]byte(ev.Params.Payload), &p); err != nil {
+		return err
+	}
+	return bot.writeRecordToFile(p)
+}
+
+// runTracks отправляет по одному чанку на каждую из tracks дорожек одновременно
+func runTracks(b *testing.B, tracks int, send func(track string) error) {
+	b.SetBytes(int64(tracks * benchChunkSize))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		errs := make(chan error, tracks)
+		for t := 0; t < tracks; t++ {
+			wg.Add(1)
+			go func(track string) {
+				defer wg.Done()
+				if err := send(track); err != nil {
+					errs <- err
+				}
+			}(fmt.Sprintf("track%d", t))
+		}
+		wg.Wait()
+		close(errs)
+		if err := <-errs; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchChunk(b *testing.B) []byte {
+	chunk := make([]byte, benchChunkSize)
+	if _, err := rand.Read(chunk); err != nil {
+		b.Fatal(err)
+	}
+	return chunk
+}
+
+func BenchmarkChunkBinding(b *testing.B) {
+	chunk := benchChunk(b)
+	for _, tracks := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("tracks=%d", tracks), func(b *testing.B) {
+			bot := &Bot{ID: "bench", DataDir: b.TempDir()}
+			runTracks(b, tracks, func(track string) error {
+				return receiveBinding(bot, bindingMessage(b, track, chunk))
+			})
+		})
+	}
+}
+
+func BenchmarkChunkUpload(b *testing.B) {
+	gin.SetMode(gin.ReleaseMode)
+	chunk := benchChunk(b)
+	for _, tracks := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("tracks=%d", tracks), func(b *testing.B) {
+			bot := &Bot{ID: "bench", DataDir: b.TempDir()}
+			s := NewChunkServer(UploadOptions{})
+			s.tokens["token"] = bot
+			router := gin.New()
+			router.POST("/chunks", s.upload)
+			srv := httptest.NewServer(router)
+			defer srv.Close()
+			client := srv.Client()
+			client.Transport.(*http.Transport).MaxIdleConnsPerHost = tracks
+
+			runTracks(b, tracks, func(track string) error {
+				req, err := http.NewRequest(http.MethodPost, srv.URL+"/chunks", bytes.NewReader(chunk))
+				if err != nil {
+					return err
+				}
+				req.Header.Set("Authorization", "Bearer token")
+				req.Header.Set(headerTrack, track)
+				req.Header.Set(headerUserID, track)
+				req.Header.Set(headerUser, track)
+				req.Header.Set(headerRoom, "bench")
+				resp, err := client.Do(req)
+				if err != nil {
+					return err
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusNoContent {
+					return fmt.Errorf("upload status %d", resp.StatusCode)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestChunkUploadShortBody(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	bot := &Bot{ID: "bot", DataDir: t.TempDir()}
+	s := NewChunkServer(UploadOptions{})
+	s.tokens["token"] = bot
+	router := gin.New()
+	router.POST("/chunks", s.upload)
+
+	tests := []struct {
+		name   string
+		body   string
+		length int64
+		status int
+	}{
+		{"complete", "0123456789", 10, http.StatusNoContent},
+		{"short", "01234", 10, http.StatusBadRequest},
+		{"broken", "01234", -1, http.StatusBadRequest},
+		{"unauthorized", "0123456789", 10, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.length < 0 {
+				// Соединение рвется после части тела неизвестной длины
+				body = io.MultiReader(body, iotest.ErrReader(io.ErrUnexpectedEOF))
+			}
+			req := httptest.NewRequest(http.MethodPost, "/chunks", body)
+			req.ContentLength = tt.length
+			if tt.status != http.StatusUnauthorized {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			req.Header.Set(headerTrack, "a1")
+			req.Header.Set(headerUserID, "u1")
+			req.Header.Set(headerRoom, "room")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+
+	// Оборванные чанки откатываются, в файле дорожки только полный
+	data, err := os.ReadFile(filepath.Join(bot.DataDir, "room", "bot", "u1_a1.webm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "0123456789" {
+		t.Errorf("track file = %q, want only the complete chunk", data)
+	}
+}
@@ -1,6 +1,7 @@
 package ssjitsi
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -28,3 +29,34 @@ func SafeFilename(filename string) string {
 
 	return safe
 }
+
+// normalizeYAMLMap converts nested map[interface{}]interface{} values produced
+// by yaml.v2 into map[string]interface{}, so the result can be JSON-encoded
+func normalizeYAMLMap(m map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		res[k] = normalizeYAML(v)
+	}
+	return res
+}
+
+func normalizeYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			res[fmt.Sprint(k)] = normalizeYAML(val)
+		}
+		return res
+	case map[string]interface{}:
+		return normalizeYAMLMap(v)
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, val := range v {
+			res[i] = normalizeYAML(val)
+		}
+		return res
+	default:
+		return v
+	}
+}
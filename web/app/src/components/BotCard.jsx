@@ -154,6 +154,31 @@ const BotCard = ({
           )}
         </div>
 
+        {/* Участники конференции */}
+        {bot.participants && bot.participants.length > 0 && (
+          <div className="mb-2 small">
+            <strong className="text-muted">Участники ({bot.participants.length}):</strong>
+            <ul className="list-unstyled mb-0" style={{ maxHeight: '96px', overflowY: 'auto' }}>
+              {bot.participants.map(p => (
+                <li key={p.id} className="d-flex justify-content-between align-items-center">
+                  <span className="text-truncate" title={p.joinedAt ? `Вошел в ${formatTime(p.joinedAt)}` : ''}>
+                    {p.recorded && <i className="bi bi-record-circle text-danger me-1" title="Записывается"></i>}
+                    {p.displayName || p.id}
+                    {p.role === 'moderator' && <span className="badge bg-info ms-1">модератор</span>}
+                    {p.connectionStatus && p.connectionStatus !== 'active' && (
+                      <i className="bi bi-wifi-off text-warning ms-1" title={`Соединение: ${p.connectionStatus}`}></i>
+                    )}
+                  </span>
+                  <span className="text-muted text-nowrap">
+                    <i className={`bi ${p.audioMuted ? 'bi-mic-mute' : 'bi-mic'}`} title={p.audioMuted ? 'Микрофон выключен' : 'Микрофон включен'}></i>
+                    <i className={`bi ${p.videoMuted ? 'bi-camera-video-off' : 'bi-camera-video'} ms-1`} title={p.videoMuted ? 'Камера выключена' : 'Камера включена'}></i>
+                  </span>
+                </li>
+              ))}
+            </ul>
+          </div>
+        )}
+
         {/* Информация о боте */}
         <div className="mt-auto">
           <div className="row small text-muted">
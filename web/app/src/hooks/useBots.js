@@ -1,5 +1,5 @@
-import { useState, useEffect, useCallback } from 'react';
-import { getBots, getScreenshot, stopBot as stopBotAPI, restartBot as restartBotAPI } from '../services/api';
+import { useState, useEffect, useCallback, useRef } from 'react';
+import { getBots, getScreenshot, getParticipants, stopBot as stopBotAPI, restartBot as restartBotAPI } from '../services/api';
 
 /**
  * Кастомный хук для управления состоянием ботов
@@ -10,6 +10,13 @@ export const useBots = () => {
   const [error, setError] = useState(null);
   const [lastUpdate, setLastUpdate] = useState(null);
 
+  // Текущий список ботов для таймеров: интервалы не пересоздаются при
+  // каждом обновлении состояния
+  const botsRef = useRef(bots);
+  useEffect(() => {
+    botsRef.current = bots;
+  }, [bots]);
+
   // Функция для загрузки списка ботов
   const loadBots = useCallback(async () => {
     try {
@@ -39,7 +46,8 @@ export const useBots = () => {
             screenshot: null,
             loadingScreenshot: false,
             screenshotError: null,
-            lastScreenshotUpdate: null
+            lastScreenshotUpdate: null,
+            participants: []
           };
         });
 
@@ -98,6 +106,33 @@ export const useBots = () => {
     }
   }, []);
 
+  // Функция для загрузки участников конференции бота
+  const loadParticipants = useCallback(async (botId) => {
+    try {
+      const participants = await getParticipants(botId);
+      setBots(prevBots =>
+        prevBots.map(bot =>
+          bot.id === botId ? { ...bot, participants: participants || [] } : bot
+        )
+      );
+    } catch (err) {
+      console.error(`Ошибка при загрузке участников бота ${botId}:`, err);
+    }
+  }, []);
+
+  // Функция для загрузки участников всех работающих ботов
+  const loadAllParticipants = useCallback(async () => {
+    for (const bot of botsRef.current) {
+      if (bot.status === 'running') {
+        await loadParticipants(bot.id);
+      } else if (bot.participants && bot.participants.length > 0) {
+        setBots(prevBots =>
+          prevBots.map(b => b.id === bot.id ? { ...b, participants: [] } : b)
+        );
+      }
+    }
+  }, [loadParticipants]);
+
   // Функция для загрузки скриншотов всех ботов
   const loadAllScreenshots = useCallback(async () => {
     const currentBots = bots;
@@ -113,7 +148,8 @@ export const useBots = () => {
     setLoading(true);
     await loadBots();
     await loadAllScreenshots();
-  }, [loadBots, loadAllScreenshots]);
+    await loadAllParticipants();
+  }, [loadBots, loadAllScreenshots, loadAllParticipants]);
 
   // Автоматическое обновление списка ботов
   useEffect(() => {
@@ -131,6 +167,14 @@ export const useBots = () => {
     }
   }, [bots.length, loadAllScreenshots]);
 
+  // Автоматическое обновление участников
+  useEffect(() => {
+    if (bots.length > 0) {
+      const interval = setInterval(loadAllParticipants, 10000); // Обновление каждые 10 секунд
+      return () => clearInterval(interval);
+    }
+  }, [bots.length, loadAllParticipants]);
+
   // Функция для остановки бота
   const stopBot = useCallback(async (botId) => {
     try {
@@ -163,6 +207,7 @@ export const useBots = () => {
     refreshAll,
     loadScreenshot,
     loadAllScreenshots,
+    loadParticipants,
     stopBot,
     restartBot
   };
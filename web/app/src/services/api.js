@@ -55,6 +55,24 @@ export const getBotHtml = async (botId) => {
   }
 };
 
+/**
+ * Получить участников конференции бота
+ * @param {string} botId - ID бота
+ * @returns {Promise<Array<{id: string, displayName: string, role: string, audioMuted: boolean, videoMuted: boolean, connectionStatus: string, joinedAt: string, recorded: boolean}>>}
+ */
+export const getParticipants = async (botId) => {
+  try {
+    const response = await fetch(`${API_BASE_URL}/${botId}/participants`);
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+    return await response.json();
+  } catch (error) {
+    console.error(`Ошибка при получении участников бота ${botId}:`, error);
+    throw error;
+  }
+};
+
 /**
  * Остановить бота
  * @param {string} botId - ID бота